@@ -53,6 +53,16 @@ func (x AffectedSlice[EcosystemSpecific, DatabaseSpecific]) HasEcosystem(ecosyst
 	return false
 }
 
+// HasPackage 判断被影响到的包中是否包含给定的包
+func (x AffectedSlice[EcosystemSpecific, DatabaseSpecific]) HasPackage(ecosystem Ecosystem, name string) bool {
+	for _, item := range x {
+		if item.Package != nil && item.Package.Ecosystem == ecosystem && item.Package.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Filter 过滤影响范围
 func (x AffectedSlice[EcosystemSpecific, DatabaseSpecific]) Filter(filterFunc func(affected *Affected[EcosystemSpecific, DatabaseSpecific]) bool) AffectedSlice[EcosystemSpecific, DatabaseSpecific] {
 	slice := make([]*Affected[EcosystemSpecific, DatabaseSpecific], 0)
@@ -120,6 +130,9 @@ type Affected[EcosystemSpecific, DatabaseSpecific any] struct {
 
 	// 由具体实现的数据库决定
 	DatabaseSpecific DatabaseSpecific `mapstructure:"database_specific" json:"database_specific" yaml:"database_specific" db:"database_specific" bson:"database_specific" gorm:"column:database_specific;serializer:json"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Affected[any, any]{}
@@ -142,3 +155,19 @@ func (x *Affected[EcosystemSpecific, DatabaseSpecific]) Scan(src any) error {
 	}
 	return json.Unmarshal(bytes, &x)
 }
+
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type affectedJson[EcosystemSpecific, DatabaseSpecific any] Affected[EcosystemSpecific, DatabaseSpecific]
+
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*affectedJson[EcosystemSpecific, DatabaseSpecific])(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Affected[EcosystemSpecific, DatabaseSpecific]) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(affectedJson[EcosystemSpecific, DatabaseSpecific](x), x.UnknownFields)
+}
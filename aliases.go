@@ -15,13 +15,21 @@ var _ driver.Valuer = &Aliases{}
 
 // GetCVE 获取别名中的CVE编号
 func (x Aliases) GetCVE() string {
-	for _, s := range x {
-		s = strings.ToUpper(s)
-		if strings.HasPrefix(s, "CVE-") {
-			return s
-		}
+	cves := x.ByDatabase("CVE")
+	if len(cves) == 0 {
+		return ""
 	}
-	return ""
+	return strings.ToUpper(cves[0])
+}
+
+// ByDatabase 过滤出属于给定漏洞库的编号，比如 ByDatabase("GHSA") ，漏洞库的前缀不区分大小写
+func (x Aliases) ByDatabase(prefix string) Aliases {
+	return filterVulnIDsByDatabase(x, prefix)
+}
+
+// VulnIDs 解析所有的编号，无法识别或者格式不正确的编号会被忽略
+func (x Aliases) VulnIDs() []*VulnID {
+	return parseVulnIDs(x)
 }
 
 // Filter 过滤出需要的编号
@@ -0,0 +1,221 @@
+package osv_schema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// CanonicalOptions 生成规范化JSON和内容哈希的选项，传nil的话使用默认选项
+type CanonicalOptions struct {
+
+	// 是否忽略 modified 字段，用来识别内容没有变化、只是重新发布了的记录
+	IgnoreModified bool
+}
+
+// 规范化时需要排序去重的字符串数组
+var canonicalSortedStringFields = []string{"aliases", "related", "upstream"}
+
+// 规范化时转换为UTC的时间字段
+var canonicalTimestampFields = []string{"modified", "published", "withdrawn"}
+
+// 规范中定义的字段，值为字段本身的子字段，叶子字段为nil，不在这里的字段（ database_specific 、 ecosystem_specific 以及不认识的字段）
+// 是数据源自己的数据，空值和不存在可能有不同的含义，规范化的时候原样保留
+type canonicalSchema map[string]canonicalSchema
+
+var (
+	canonicalEventSchema     = canonicalSchema{"introduced": nil, "fixed": nil, "last_affected": nil, "limit": nil}
+	canonicalRangeSchema     = canonicalSchema{"type": nil, "repo": nil, "events": canonicalEventSchema}
+	canonicalPackageSchema   = canonicalSchema{"ecosystem": nil, "name": nil, "purl": nil}
+	canonicalSeveritySchema  = canonicalSchema{"type": nil, "score": nil}
+	canonicalReferenceSchema = canonicalSchema{"type": nil, "url": nil}
+	canonicalCreditsSchema   = canonicalSchema{"name": nil, "contact": nil, "type": nil}
+	canonicalAffectedSchema  = canonicalSchema{
+		"package": canonicalPackageSchema, "severity": canonicalSeveritySchema, "ranges": canonicalRangeSchema, "versions": nil,
+	}
+	canonicalRecordSchema = canonicalSchema{
+		"schema_version": nil, "id": nil, "modified": nil, "published": nil, "withdrawn": nil, "aliases": nil, "related": nil,
+		"upstream": nil, "summary": nil, "details": nil, "severity": canonicalSeveritySchema, "affected": canonicalAffectedSchema,
+		"references": canonicalReferenceSchema, "credits": canonicalCreditsSchema,
+	}
+)
+
+// CanonicalJson 返回规范化的JSON，同样内容的记录不管字段顺序、时区、数组顺序怎么变化都会得到相同的字节：
+// 对象的key按照字典序排列，没有缩进；规范中定义的字段的null、空字符串、空数组、空对象以及零值时间会被去掉；
+// aliases 、 related 、 upstream 排序并去重， references 排序；时间统一转换为UTC。
+// database_specific 、 ecosystem_specific 和不认识的字段只按照key排序，其它原样保留
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) CanonicalJson(options *CanonicalOptions) ([]byte, error) {
+	if options == nil {
+		options = &CanonicalOptions{}
+	}
+	jsonBytes, err := json.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	if options.IgnoreModified {
+		delete(document, "modified")
+	}
+	for _, field := range canonicalTimestampFields {
+		s, ok := document[field].(string)
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			if t.IsZero() {
+				delete(document, field)
+			} else {
+				document[field] = t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	for _, field := range canonicalSortedStringFields {
+		if values, ok := document[field].([]any); ok {
+			document[field] = sortCanonicalStrings(values)
+		}
+	}
+
+	value, _ := dropEmptyJsonValues(document, canonicalRecordSchema)
+	if references, ok := value.(map[string]any)["references"].([]any); ok {
+		sorted, err := sortCanonicalValuesByJson(references)
+		if err != nil {
+			return nil, err
+		}
+		value.(map[string]any)["references"] = sorted
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+// ContentHash 返回规范化JSON的sha256，十六进制编码，@see CanonicalJson 。
+// 可以用来判断记录的内容是否真的发生了变化，或者给来自不同镜像的相同记录去重
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ContentHash(options *CanonicalOptions) (string, error) {
+	canonical, err := x.CanonicalJson(options)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// DeduplicateByContent 去掉内容相同的记录，内容相同的记录只保留第一条，@see ContentHash
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) DeduplicateByContent(options *CanonicalOptions) (OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific], error) {
+	slice := make([]*OsvSchema[EcosystemSpecific, DatabaseSpecific], 0, len(x))
+	seen := make(map[string]bool)
+	for _, item := range x {
+		if item == nil {
+			continue
+		}
+		hash, err := item.ContentHash(options)
+		if err != nil {
+			return nil, err
+		}
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		slice = append(slice, item)
+	}
+	return slice, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 递归地去掉规范中定义的字段的null、空字符串、空数组和空对象，第二个返回值表示这个值本身是否为空，
+// 对象中不在schema里的字段原样保留，数组中的元素使用和数组相同的schema
+func dropEmptyJsonValues(value any, schema canonicalSchema) (any, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return v, v == ""
+	case []any:
+		values := make([]any, 0, len(v))
+		for _, item := range v {
+			if item, empty := dropEmptyJsonValues(item, schema); !empty {
+				values = append(values, item)
+			}
+		}
+		return values, len(values) == 0
+	case map[string]any:
+		for key, item := range v {
+			child, known := schema[key]
+			if !known {
+				continue
+			}
+			if item, empty := dropEmptyJsonValues(item, child); empty {
+				delete(v, key)
+			} else {
+				v[key] = item
+			}
+		}
+		return v, len(v) == 0
+	}
+	return value, false
+}
+
+// 字符串数组排序并去重，数组中有其它类型的值的时候原样返回
+func sortCanonicalStrings(values []any) []any {
+	texts := make([]string, 0, len(values))
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return values
+		}
+		texts = append(texts, s)
+	}
+	sort.Strings(texts)
+	sorted := make([]any, 0, len(texts))
+	for i, s := range texts {
+		if i > 0 && texts[i-1] == s {
+			continue
+		}
+		sorted = append(sorted, s)
+	}
+	return sorted
+}
+
+// 按照每个元素的规范化JSON排序
+func sortCanonicalValuesByJson(values []any) ([]any, error) {
+	keys := make([]string, len(values))
+	for i, value := range values {
+		buffer := &bytes.Buffer{}
+		encoder := json.NewEncoder(buffer)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(value); err != nil {
+			return nil, err
+		}
+		keys[i] = buffer.String()
+	}
+	indexes := make([]int, len(values))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return keys[indexes[i]] < keys[indexes[j]]
+	})
+	sorted := make([]any, 0, len(values))
+	for _, index := range indexes {
+		sorted = append(sorted, values[index])
+	}
+	return sorted, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
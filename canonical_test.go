@@ -0,0 +1,72 @@
+package osv_schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalJson(t *testing.T) {
+	a, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"modified": "2024-01-02T11:04:05+08:00",
+		"published": "2024-01-01T00:00:00Z",
+		"aliases": ["CVE-2024-2", "CVE-2024-1", "CVE-2024-2"],
+		"summary": "",
+		"references": [{"type": "WEB", "url": "https://b.example"}, {"type": "ADVISORY", "url": "https://a.example"}],
+		"database_specific": {"z": 1, "b": {"c": null}},
+		"x_custom": {"b": 2, "a": 1}
+	}`))
+	assert.Nil(t, err)
+	b, err := UnmarshalFromJson[any, any]([]byte(`{
+		"x_custom": {"a": 1, "b": 2},
+		"references": [{"url": "https://a.example", "type": "ADVISORY"}, {"url": "https://b.example", "type": "WEB"}],
+		"aliases": ["CVE-2024-1", "CVE-2024-2"],
+		"published": "2024-01-01T00:00:00.000Z",
+		"modified": "2024-01-02T03:04:05Z",
+		"database_specific": {"b": {"c": null}, "z": 1},
+		"id": "GHSA-1"
+	}`))
+	assert.Nil(t, err)
+
+	canonical, err := a.CanonicalJson(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"aliases":["CVE-2024-1","CVE-2024-2"],"database_specific":{"b":{"c":null},"z":1},"id":"GHSA-1","modified":"2024-01-02T03:04:05Z","published":"2024-01-01T00:00:00Z","references":[{"type":"ADVISORY","url":"https://a.example"},{"type":"WEB","url":"https://b.example"}],"x_custom":{"a":1,"b":2}}`, string(canonical))
+
+	hashA, err := a.ContentHash(nil)
+	assert.Nil(t, err)
+	hashB, err := b.ContentHash(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, hashA, hashB)
+	assert.Len(t, hashA, 64)
+
+	// 只有modified不同
+	b.Modified = b.Modified.AddDate(0, 1, 0)
+	hashB, err = b.ContentHash(nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, hashA, hashB)
+	hashA, err = a.ContentHash(&CanonicalOptions{IgnoreModified: true})
+	assert.Nil(t, err)
+	hashB, err = b.ContentHash(&CanonicalOptions{IgnoreModified: true})
+	assert.Nil(t, err)
+	assert.Equal(t, hashA, hashB)
+
+	b.Summary = "changed"
+	deduplicated, err := OsvSchemaSlice[any, any]{a, a, b}.DeduplicateByContent(&CanonicalOptions{IgnoreModified: true})
+	assert.Nil(t, err)
+	assert.Len(t, deduplicated, 2)
+
+	// database_specific 是数据源自己的数据，空值和不存在是不同的
+	empty, err := UnmarshalFromJson[any, any]([]byte(`{"id": "GHSA-1", "database_specific": {}, "summary": ""}`))
+	assert.Nil(t, err)
+	emptyArray, err := UnmarshalFromJson[any, any]([]byte(`{"id": "GHSA-1", "database_specific": {"a": []}}`))
+	assert.Nil(t, err)
+	canonical, err = emptyArray.CanonicalJson(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"database_specific":{"a":[]},"id":"GHSA-1"}`, string(canonical))
+	hashA, err = empty.ContentHash(nil)
+	assert.Nil(t, err)
+	hashB, err = emptyArray.ContentHash(nil)
+	assert.Nil(t, err)
+	assert.NotEqual(t, hashA, hashB)
+}
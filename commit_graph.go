@@ -0,0 +1,254 @@
+package osv_schema
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// CommitGraph Git仓库的提交图，用于计算GIT类型的范围
+type CommitGraph interface {
+
+	// IsAncestor 判断 ancestor 是否是 descendant 的祖先（可能是很远的祖先），同一个提交也认为是自己的祖先
+	IsAncestor(ancestor, descendant string) (bool, error)
+}
+
+// ErrCommitNotFound 提交图中没有这个提交
+var ErrCommitNotFound = errors.New("commit not found")
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// LocalGitCommitGraph 从本地磁盘上的Git仓库中读取的提交图，创建的时候会把所有引用能够到达的提交一次性加载到内存中，
+// 之后的查询不会再访问仓库，仓库有更新的话需要重新创建
+type LocalGitCommitGraph struct {
+
+	// 仓库所在的目录
+	Dir string
+
+	// 提交哈希到下标的映射
+	index map[string]int
+
+	// 每个提交的哈希
+	hashes []string
+
+	// 每个提交的父提交的下标
+	parents [][]int
+
+	// 每个提交的代数，没有父提交的提交为1，其它提交为父提交的最大代数加1，祖先的代数一定比后代小，用于剪枝
+	generations []int
+}
+
+var _ CommitGraph = &LocalGitCommitGraph{}
+
+// NewLocalGitCommitGraph 读取本地Git仓库的提交图，需要系统中安装有git命令
+func NewLocalGitCommitGraph(dir string) (*LocalGitCommitGraph, error) {
+	output, err := runGit(dir, "rev-list", "--all", "--topo-order", "--parents")
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &LocalGitCommitGraph{Dir: dir, index: make(map[string]int)}
+	var parentHashes [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		graph.index[fields[0]] = len(graph.hashes)
+		graph.hashes = append(graph.hashes, fields[0])
+		parentHashes = append(parentHashes, fields[1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	graph.parents = make([][]int, len(graph.hashes))
+	for i, hashes := range parentHashes {
+		for _, hash := range hashes {
+			if parent, ok := graph.index[hash]; ok {
+				graph.parents[i] = append(graph.parents[i], parent)
+			}
+		}
+	}
+
+	// 拓扑序中后代一定在祖先的前面，所以倒着计算代数
+	graph.generations = make([]int, len(graph.hashes))
+	for i := len(graph.hashes) - 1; i >= 0; i-- {
+		generation := 1
+		for _, parent := range graph.parents[i] {
+			if graph.generations[parent]+1 > generation {
+				generation = graph.generations[parent] + 1
+			}
+		}
+		graph.generations[i] = generation
+	}
+	return graph, nil
+}
+
+// HasCommit 判断提交图中是否有这个提交
+func (x *LocalGitCommitGraph) HasCommit(commit string) bool {
+	_, ok := x.index[strings.ToLower(commit)]
+	return ok
+}
+
+// IsAncestor 判断 ancestor 是否是 descendant 的祖先，提交不存在的时候返回 ErrCommitNotFound
+func (x *LocalGitCommitGraph) IsAncestor(ancestor, descendant string) (bool, error) {
+	ancestorIndex, err := x.lookup(ancestor)
+	if err != nil {
+		return false, err
+	}
+	descendantIndex, err := x.lookup(descendant)
+	if err != nil {
+		return false, err
+	}
+	if ancestorIndex == descendantIndex {
+		return true, nil
+	}
+	ancestorGeneration := x.generations[ancestorIndex]
+	if ancestorGeneration >= x.generations[descendantIndex] {
+		return false, nil
+	}
+
+	visited := map[int]struct{}{descendantIndex: {}}
+	queue := []int{descendantIndex}
+	for len(queue) != 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range x.parents[current] {
+			if parent == ancestorIndex {
+				return true, nil
+			}
+			// 代数不大于祖先的提交不可能再到达祖先了
+			if x.generations[parent] <= ancestorGeneration {
+				continue
+			}
+			if _, ok := visited[parent]; ok {
+				continue
+			}
+			visited[parent] = struct{}{}
+			queue = append(queue, parent)
+		}
+	}
+	return false, nil
+}
+
+func (x *LocalGitCommitGraph) lookup(commit string) (int, error) {
+	index, ok := x.index[strings.ToLower(commit)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s in %s", ErrCommitNotFound, commit, x.Dir)
+	}
+	return index, nil
+}
+
+// 在给定的目录中执行git命令，返回标准输出
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// IsCommitAffected 判断给定的提交是否在这个GIT类型的范围中，规则为：
+// 1. 提交必须是某个 introduced 的后代（包括它自己），introduced 为 0 表示从第一个提交开始
+// 2. 提交不能是任何一个 fixed 的后代（包括它自己）
+// 3. 提交不能是任何一个 last_affected 的严格后代
+// 4. 如果有 limit 的话，提交必须是某个 limit 的严格祖先
+func (x *Range[DatabaseSpecific]) IsCommitAffected(graph CommitGraph, commit string) (bool, error) {
+	if x.Type != RangeTypeGit {
+		return false, fmt.Errorf("range type %s is not %s", x.Type, RangeTypeGit)
+	}
+
+	introduced := false
+	hasLimit := false
+	for _, event := range x.Events {
+		if event == nil {
+			continue
+		}
+		switch {
+		case event.IsIntroduced():
+			if introduced {
+				continue
+			}
+			if event.Introduced == "0" {
+				introduced = true
+				continue
+			}
+			isAncestor, err := graph.IsAncestor(event.Introduced, commit)
+			if err != nil {
+				return false, err
+			}
+			introduced = isAncestor
+		case event.IsFixed():
+			isAncestor, err := graph.IsAncestor(event.Fixed, commit)
+			if err != nil {
+				return false, err
+			}
+			if isAncestor {
+				return false, nil
+			}
+		case event.IsLastAffected():
+			if strings.EqualFold(event.LastAffected, commit) {
+				continue
+			}
+			isAncestor, err := graph.IsAncestor(event.LastAffected, commit)
+			if err != nil {
+				return false, err
+			}
+			if isAncestor {
+				return false, nil
+			}
+		case event.IsLimit():
+			hasLimit = true
+		}
+	}
+	if !introduced || !hasLimit {
+		return introduced, nil
+	}
+
+	for _, event := range x.Events {
+		if event == nil || !event.IsLimit() || strings.EqualFold(event.Limit, commit) {
+			continue
+		}
+		isAncestor, err := graph.IsAncestor(commit, event.Limit)
+		if err != nil {
+			return false, err
+		}
+		if isAncestor {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsCommitAffected 判断给定仓库的提交是否被这个影响范围中的GIT类型的范围影响到了，只会计算仓库地址相同的范围
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) IsCommitAffected(graph CommitGraph, repo, commit string) (bool, error) {
+	key := repoKey(repo)
+	for _, r := range x.Ranges {
+		if r == nil || r.Type != RangeTypeGit || repoKey(r.Repo) != key {
+			continue
+		}
+		affected, err := r.IsCommitAffected(graph, commit)
+		if err != nil {
+			return false, err
+		}
+		if affected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,101 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// 创建一个测试用的仓库，提交图为：
+//
+//	c1 -- c2 -- c3 (main, v1.1)
+//	        \
+//	         c4 -- c5 (release, v1.0.1)
+//
+// c2 上有标签 v1.0
+func newTestGitRepo(t *testing.T) (string, map[string]string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		args = append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)
+		output, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(message string) string {
+		git("commit", "--allow-empty", "-q", "-m", message)
+		return git("rev-parse", "HEAD")
+	}
+	git("init", "-q", "-b", "main")
+	commits := make(map[string]string)
+	commits["c1"] = commit("c1")
+	commits["c2"] = commit("c2")
+	git("tag", "-a", "v1.0", "-m", "v1.0")
+	commits["c3"] = commit("c3")
+	git("tag", "v1.1")
+	git("checkout", "-q", "-b", "release", commits["c2"])
+	commits["c4"] = commit("c4")
+	commits["c5"] = commit("c5")
+	git("tag", "v1.0.1")
+	return dir, commits
+}
+
+func TestRange_IsCommitAffected(t *testing.T) {
+	dir, commits := newTestGitRepo(t)
+	graph, err := NewLocalGitCommitGraph(dir)
+	assert.Nil(t, err)
+
+	ok, err := graph.IsAncestor(commits["c1"], commits["c5"])
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = graph.IsAncestor(commits["c3"], commits["c5"])
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	r := &Range[any]{Type: RangeTypeGit, Events: Events{{Introduced: commits["c2"]}, {Fixed: commits["c3"]}}}
+	for name, expected := range map[string]bool{"c1": false, "c2": true, "c3": false, "c4": true, "c5": true} {
+		affected, err := r.IsCommitAffected(graph, commits[name])
+		assert.Nil(t, err)
+		assert.Equal(t, expected, affected, name)
+	}
+
+	r = &Range[any]{Type: RangeTypeGit, Events: Events{{Introduced: "0"}, {LastAffected: commits["c4"]}}}
+	for name, expected := range map[string]bool{"c1": true, "c3": true, "c4": true, "c5": false} {
+		affected, err := r.IsCommitAffected(graph, commits[name])
+		assert.Nil(t, err)
+		assert.Equal(t, expected, affected, name)
+	}
+
+	r = &Range[any]{Type: RangeTypeGit, Events: Events{{Introduced: commits["c2"]}, {Limit: commits["c5"]}}}
+	for name, expected := range map[string]bool{"c2": true, "c3": false, "c4": true, "c5": false} {
+		affected, err := r.IsCommitAffected(graph, commits[name])
+		assert.Nil(t, err)
+		assert.Equal(t, expected, affected, name)
+	}
+
+	_, err = r.IsCommitAffected(graph, "0000000000000000000000000000000000000000")
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+}
+
+func TestAffected_EnumerateGitVersions(t *testing.T) {
+	dir, commits := newTestGitRepo(t)
+	graph, err := NewLocalGitCommitGraph(dir)
+	assert.Nil(t, err)
+
+	tags, err := graph.Tags()
+	assert.Nil(t, err)
+	assert.Len(t, tags, 3)
+
+	affected := &Affected[any, any]{
+		Ranges: []*Range[any]{{Type: RangeTypeGit, Repo: "https://example.com/repo", Events: Events{{Introduced: commits["c2"]}, {Fixed: commits["c3"]}}}},
+	}
+	added, err := affected.EnumerateGitVersions("https://example.com/repo.git", graph, &GitVersionsOptions{NormalizeTag: TrimTagPrefix("v")})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0", "1.0.1"}, added)
+	assert.Equal(t, []string{"1.0", "1.0.1"}, affected.Versions)
+}
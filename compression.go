@@ -0,0 +1,150 @@
+package osv_schema
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// Decompressor 一种压缩格式的解压器，读取文件的时候会根据文件开头的魔数自动识别压缩格式并解压
+type Decompressor struct {
+
+	// 压缩格式的名字，比如 gzip ，重复注册同名的解压器会覆盖之前的
+	Name string
+
+	// 压缩文件开头的魔数
+	Magic []byte
+
+	// 压缩文件的扩展名，比如 .gz ，加载目录时会去掉这个扩展名再判断文件的格式，比如 GHSA-xxx.json.gz 按照JSON解析
+	Extensions []string
+
+	// 创建解压的Reader
+	NewReader func(reader io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	decompressorsLock sync.RWMutex
+	decompressors     = make([]*Decompressor, 0)
+)
+
+func init() {
+	RegisterDecompressor(&Decompressor{
+		Name:       "gzip",
+		Magic:      []byte{0x1f, 0x8b},
+		Extensions: []string{".gz", ".gzip"},
+		NewReader: func(reader io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(reader)
+		},
+	})
+	RegisterDecompressor(&Decompressor{
+		Name:       "bzip2",
+		Magic:      []byte("BZh"),
+		Extensions: []string{".bz2"},
+		NewReader: func(reader io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(reader)), nil
+		},
+	})
+}
+
+// RegisterDecompressor 注册解压器，标准库没有的压缩格式可以通过这个方法接入，比如zstd：
+//
+//	osv_schema.RegisterDecompressor(&osv_schema.Decompressor{
+//		Name:       "zstd",
+//		Magic:      []byte{0x28, 0xb5, 0x2f, 0xfd},
+//		Extensions: []string{".zst"},
+//		NewReader: func(reader io.Reader) (io.ReadCloser, error) {
+//			decoder, err := zstd.NewReader(reader)
+//			if err != nil {
+//				return nil, err
+//			}
+//			return decoder.IOReadCloser(), nil
+//		},
+//	})
+func RegisterDecompressor(decompressor *Decompressor) {
+	decompressorsLock.Lock()
+	defer decompressorsLock.Unlock()
+	for i, registered := range decompressors {
+		if registered.Name == decompressor.Name {
+			decompressors[i] = decompressor
+			return
+		}
+	}
+	decompressors = append(decompressors, decompressor)
+}
+
+// NewDecompressReader 根据开头的魔数识别压缩格式，返回解压之后的Reader，没有压缩的话原样读取，关闭的时候不会关闭传入的reader
+func NewDecompressReader(reader io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(reader)
+	decompressorsLock.RLock()
+	defer decompressorsLock.RUnlock()
+	for _, decompressor := range decompressors {
+		header, _ := buffered.Peek(len(decompressor.Magic))
+		if len(decompressor.Magic) != 0 && bytes.Equal(header, decompressor.Magic) {
+			return decompressor.NewReader(buffered)
+		}
+	}
+	return io.NopCloser(buffered), nil
+}
+
+// 去掉压缩格式的扩展名，比如 GHSA-xxx.json.gz 返回 GHSA-xxx.json
+func trimCompressionExtension(filePath string) string {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	decompressorsLock.RLock()
+	defer decompressorsLock.RUnlock()
+	for _, decompressor := range decompressors {
+		for _, extension := range decompressor.Extensions {
+			if strings.ToLower(extension) == ext {
+				return filePath[:len(filePath)-len(ext)]
+			}
+		}
+	}
+	return filePath
+}
+
+// 打开文件，压缩过的文件会被自动解压，关闭的时候会同时关闭文件
+func openFileDecompressed(filePath string) (io.ReadCloser, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := NewDecompressReader(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return &decompressedFile{ReadCloser: reader, file: file}, nil
+}
+
+type decompressedFile struct {
+	io.ReadCloser
+	file *os.File
+}
+
+func (x *decompressedFile) Close() error {
+	err := x.ReadCloser.Close()
+	if closeErr := x.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// 读取整个文件，压缩过的文件会被自动解压
+func readFileDecompressed(filePath string) ([]byte, error) {
+	reader, err := openFileDecompressed(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,97 @@
+package osv_schema
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	_, err := writer.Write([]byte(data))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func TestNewDecompressReader(t *testing.T) {
+	reader, err := NewDecompressReader(bytes.NewReader(gzipBytes(t, `{"id": "GHSA-1"}`)))
+	assert.Nil(t, err)
+	data, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id": "GHSA-1"}`, string(data))
+
+	reader, err = NewDecompressReader(bytes.NewReader([]byte(`{"id": "GHSA-2"}`)))
+	assert.Nil(t, err)
+	data, err = io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"id": "GHSA-2"}`, string(data))
+
+	// 注册自定义的解压器
+	RegisterDecompressor(&Decompressor{
+		Name:       "test-rot",
+		Magic:      []byte("ROT1"),
+		Extensions: []string{".rot"},
+		NewReader: func(reader io.Reader) (io.ReadCloser, error) {
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+			data = data[4:]
+			for i := range data {
+				data[i]--
+			}
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	})
+	reader, err = NewDecompressReader(bytes.NewReader([]byte("ROT1|\"jie\"")))
+	assert.Nil(t, err)
+	data, err = io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "{!ihd!", string(data)[:6])
+	assert.Equal(t, "GHSA-1.json", trimCompressionExtension("GHSA-1.json.rot"))
+	assert.Equal(t, "GHSA-1.json", trimCompressionExtension("GHSA-1.json.GZ"))
+	assert.Equal(t, "GHSA-1.json", trimCompressionExtension("GHSA-1.json"))
+}
+
+func TestCompressedFiles(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "GHSA-1.json.gz"), gzipBytes(t, `{"id": "GHSA-1"}`), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "PYSEC-2.yaml.gz"), gzipBytes(t, "id: PYSEC-2\n"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "GHSA-3.json"), []byte(`{"id": "GHSA-3"}`), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "all.jsonl.gz"), gzipBytes(t, "{\"id\": \"A\"}\n{\"id\": \"B\"}\n"), 0644))
+
+	r, err := UnmarshalFromJsonFile[any, any](filepath.Join(root, "GHSA-1.json.gz"))
+	assert.Nil(t, err)
+	assert.Equal(t, "GHSA-1", r.ID)
+
+	r, err = UnmarshalFromYamlFile[any, any](filepath.Join(root, "PYSEC-2.yaml.gz"))
+	assert.Nil(t, err)
+	assert.Equal(t, "PYSEC-2", r.ID)
+
+	decoder, err := OpenStreamFile[any, any](filepath.Join(root, "all.jsonl.gz"), nil)
+	assert.Nil(t, err)
+	ids, recordErrors := readAllRecords(decoder)
+	assert.Nil(t, decoder.Close())
+	assert.Empty(t, recordErrors)
+	assert.Equal(t, []string{"A", "B"}, ids)
+
+	records, err := LoadDirectory[any, any](context.Background(), root, nil)
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, "GHSA-1", records[0].Record.ID)
+	assert.Equal(t, "GHSA-3", records[1].Record.ID)
+	assert.Equal(t, "PYSEC-2", records[2].Record.ID)
+
+	// 扩展名是压缩格式但是内容已经损坏
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "bad.json.gz"), gzipBytes(t, `{"id": "GHSA-1"}`)[:12], 0644))
+	_, err = UnmarshalFromJsonFile[any, any](filepath.Join(root, "bad.json.gz"))
+	assert.NotNil(t, err)
+}
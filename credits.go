@@ -4,8 +4,102 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
 )
 
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// CreditsSlice 表示漏洞的所有贡献者
+// Example:
+//
+//	"credits": [
+//	  {
+//	    "name": "Google OSS-Fuzz",
+//	    "contact": ["https://github.com/google/oss-fuzz"],
+//	    "type": "FINDER"
+//	  }
+//	]
+type CreditsSlice []*Credits
+
+var _ sql.Scanner = &CreditsSlice{}
+var _ driver.Valuer = &CreditsSlice{}
+
+// Filter 过滤贡献者
+func (x CreditsSlice) Filter(filterFunc func(credits *Credits) bool) CreditsSlice {
+	slice := make([]*Credits, 0)
+	for _, item := range x {
+		if item != nil && filterFunc(item) {
+			slice = append(slice, item)
+		}
+	}
+	return slice
+}
+
+// FilterByType 过滤出给定角色的贡献者
+func (x CreditsSlice) FilterByType(creditsTypes ...CreditsType) CreditsSlice {
+	return x.Filter(func(credits *Credits) bool {
+		for _, creditsType := range creditsTypes {
+			if credits.Type == creditsType {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Finders 返回所有发现了这个漏洞的贡献者
+func (x CreditsSlice) Finders() CreditsSlice {
+	return x.FilterByType(CreditsTypeFinder)
+}
+
+// Reporters 返回所有报告了这个漏洞的贡献者
+func (x CreditsSlice) Reporters() CreditsSlice {
+	return x.FilterByType(CreditsTypeReporter)
+}
+
+// Validate 校验所有贡献者的角色是否是规范中定义的角色
+func (x CreditsSlice) Validate() error {
+	for i, credits := range x {
+		if err := credits.Validate(); err != nil {
+			return fmt.Errorf("credits[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (x *CreditsSlice) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	bytes, ok := src.([]byte)
+	if !ok {
+		return wrapScanError(src, x)
+	}
+	if len(bytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bytes, &x)
+}
+
+func (x CreditsSlice) Value() (driver.Value, error) {
+	if len(x) == 0 {
+		return nil, nil
+	}
+	marshal, err := json.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	if len(marshal) == 0 {
+		return nil, nil
+	}
+	return string(marshal), nil
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// CreditsType 贡献者在这个漏洞中的角色
 type CreditsType string
 
 const (
@@ -40,10 +134,40 @@ const (
 	CreditsTypeOther CreditsType = "OTHER"
 )
 
+// IsValid 判断是否是规范中定义的角色
+func (x CreditsType) IsValid() bool {
+	switch x {
+	case CreditsTypeFinder, CreditsTypeReporter, CreditsTypeAnalyst, CreditsTypeCoordinator, CreditsTypeRemediationDeveloper,
+		CreditsTypeRemediationReviewer, CreditsTypeRemediationVerifier, CreditsTypeTool, CreditsTypeSponsor, CreditsTypeOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// Credits 漏洞的一个贡献者
+// Example:
+//
+//	{
+//	  "name": "Google OSS-Fuzz",
+//	  "contact": ["https://github.com/google/oss-fuzz"],
+//	  "type": "FINDER"
+//	}
 type Credits struct {
-	Name    string   `mapstructure:"name" json:"name" yaml:"name" db:"name" bson:"name" gorm:"column:name"`
+
+	// 贡献者的名字
+	Name string `mapstructure:"name" json:"name" yaml:"name" db:"name" bson:"name" gorm:"column:name"`
+
+	// 贡献者的联系方式，可以是邮箱、网址、社交账号等，@see ParseContact
 	Contact []string `mapstructure:"contact" json:"contact" yaml:"contact" db:"contact" bson:"contact" gorm:"column:contact;serializer:json"`
-	Type    string   `mapstructure:"type" json:"type" yaml:"type" db:"type" bson:"type" gorm:"column:type"`
+
+	// 贡献者的角色，可选
+	Type CreditsType `mapstructure:"type" json:"type" yaml:"type" db:"type" bson:"type" gorm:"column:type"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Credits{}
@@ -66,3 +190,135 @@ func (x *Credits) Scan(src any) error {
 	}
 	return json.Unmarshal(bytes, &x)
 }
+
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type creditsJson Credits
+
+func (x *Credits) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*creditsJson)(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Credits) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(creditsJson(x), x.UnknownFields)
+}
+
+// Validate 校验贡献者的角色是否是规范中定义的角色，角色是可选的，为空的时候认为是合法的
+func (x *Credits) Validate() error {
+	if x == nil {
+		return nil
+	}
+	if x.Type != "" && !x.Type.IsValid() {
+		return fmt.Errorf("invalid credits type %q for %s", x.Type, x.Name)
+	}
+	return nil
+}
+
+// ParseContacts 解析贡献者的所有联系方式
+func (x *Credits) ParseContacts() []*Contact {
+	if x == nil {
+		return nil
+	}
+	contacts := make([]*Contact, 0, len(x.Contact))
+	for _, contact := range x.Contact {
+		contacts = append(contacts, ParseContact(contact))
+	}
+	return contacts
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// ContactType 联系方式的类型
+type ContactType string
+
+const (
+
+	// ContactTypeEmail 邮箱，比如 mailto:foo@example.com 或者 foo@example.com
+	ContactTypeEmail ContactType = "email"
+
+	// ContactTypeGitHub GitHub账号，比如 https://github.com/foo
+	ContactTypeGitHub ContactType = "github"
+
+	// ContactTypeTwitter Twitter或者X账号，比如 https://twitter.com/foo 或者 https://x.com/foo
+	ContactTypeTwitter ContactType = "twitter"
+
+	// ContactTypeMastodon Mastodon账号，比如 @foo@infosec.exchange 或者 https://infosec.exchange/@foo
+	ContactTypeMastodon ContactType = "mastodon"
+
+	// ContactTypeHandle 不知道是哪个平台的社交账号，比如 @foo
+	ContactTypeHandle ContactType = "handle"
+
+	// ContactTypeWeb 其它网址
+	ContactTypeWeb ContactType = "web"
+
+	// ContactTypeOther 无法识别的联系方式
+	ContactTypeOther ContactType = "other"
+)
+
+// Contact 解析后的联系方式
+type Contact struct {
+
+	// 联系方式的类型
+	Type ContactType
+
+	// 联系方式中的关键信息，邮箱为邮箱地址，社交账号为账号名，网址为网址本身，其它为原始字符串
+	Value string
+
+	// 能够访问到这个联系方式的链接，邮箱是 mailto: 链接，账号是个人主页，无法识别的联系方式为空
+	URL string
+
+	// 原始的联系方式
+	Raw string
+}
+
+// ParseContact 解析贡献者的联系方式
+func ParseContact(contact string) *Contact {
+	raw := contact
+	contact = strings.TrimSpace(contact)
+	lower := strings.ToLower(contact)
+
+	switch {
+	case strings.HasPrefix(lower, "mailto:"):
+		address := contact[len("mailto:"):]
+		return &Contact{Type: ContactTypeEmail, Value: address, URL: "mailto:" + address, Raw: raw}
+	case strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://"):
+		return parseContactURL(contact, raw)
+	case strings.HasPrefix(contact, "@"):
+		handle := contact[1:]
+		if index := strings.Index(handle, "@"); index > 0 {
+			return &Contact{Type: ContactTypeMastodon, Value: contact, URL: "https://" + handle[index+1:] + "/@" + handle[:index], Raw: raw}
+		}
+		return &Contact{Type: ContactTypeHandle, Value: handle, Raw: raw}
+	case strings.Count(contact, "@") == 1 && !strings.ContainsAny(contact, " /") && strings.Contains(contact[strings.Index(contact, "@"):], "."):
+		return &Contact{Type: ContactTypeEmail, Value: contact, URL: "mailto:" + contact, Raw: raw}
+	default:
+		return &Contact{Type: ContactTypeOther, Value: contact, Raw: raw}
+	}
+}
+
+// 解析网址形式的联系方式
+func parseContactURL(contact, raw string) *Contact {
+	u, err := url.Parse(contact)
+	if err != nil || u.Host == "" {
+		return &Contact{Type: ContactTypeOther, Value: contact, Raw: raw}
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	firstSegment := segments[0]
+	if len(segments) == 1 && firstSegment != "" {
+		switch host {
+		case "github.com":
+			return &Contact{Type: ContactTypeGitHub, Value: firstSegment, URL: contact, Raw: raw}
+		case "twitter.com", "x.com":
+			return &Contact{Type: ContactTypeTwitter, Value: strings.TrimPrefix(firstSegment, "@"), URL: contact, Raw: raw}
+		}
+		if strings.HasPrefix(firstSegment, "@") && len(firstSegment) > 1 {
+			return &Contact{Type: ContactTypeMastodon, Value: firstSegment + "@" + host, URL: contact, Raw: raw}
+		}
+	}
+	return &Contact{Type: ContactTypeWeb, Value: contact, URL: contact, Raw: raw}
+}
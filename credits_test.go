@@ -0,0 +1,37 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreditsSlice(t *testing.T) {
+	r, err := UnmarshalFromJson[any, any]([]byte(`{"id": "OSV-1", "credits": [
+		{"name": "Alice", "contact": ["mailto:alice@example.com", "https://twitter.com/alice"], "type": "FINDER"},
+		{"name": "Bob", "contact": ["@bob@infosec.exchange"], "type": "CHEERLEADER"}
+	]}`))
+	assert.Nil(t, err)
+	assert.Len(t, r.Credits, 2)
+	assert.Equal(t, "Alice", r.Credits.Finders()[0].Name)
+	assert.NotNil(t, r.Credits.Validate())
+
+	contacts := r.Credits[0].ParseContacts()
+	assert.Equal(t, &Contact{Type: ContactTypeEmail, Value: "alice@example.com", URL: "mailto:alice@example.com", Raw: "mailto:alice@example.com"}, contacts[0])
+	assert.Equal(t, ContactTypeTwitter, contacts[1].Type)
+	assert.Equal(t, "alice", contacts[1].Value)
+
+	// nil元素会被跳过
+	withNil := CreditsSlice{nil, r.Credits[0], nil}
+	assert.Len(t, withNil.Finders(), 1)
+	assert.Len(t, withNil.Filter(func(credits *Credits) bool { return true }), 1)
+}
+
+func TestParseContact(t *testing.T) {
+	assert.Equal(t, ContactTypeEmail, ParseContact("bob@example.com").Type)
+	assert.Equal(t, ContactTypeGitHub, ParseContact("https://github.com/bob").Type)
+	assert.Equal(t, "https://infosec.exchange/@bob", ParseContact("@bob@infosec.exchange").URL)
+	assert.Equal(t, "@bob@infosec.exchange", ParseContact("https://infosec.exchange/@bob").Value)
+	assert.Equal(t, ContactTypeHandle, ParseContact("@bob").Type)
+	assert.Equal(t, ContactTypeWeb, ParseContact("https://example.com/security").Type)
+	assert.Equal(t, ContactTypeOther, ParseContact("Bob Smith").Type)
+}
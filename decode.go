@@ -0,0 +1,245 @@
+package osv_schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// DecodeOptions 从 io.Reader 中解码漏洞记录的选项，各种限制为0的时候表示不限制，传nil的话不做任何限制
+type DecodeOptions struct {
+
+	// 文档的最大字节数
+	MaxDocumentSize int64
+
+	// affected 数组的最大长度
+	MaxAffected int
+
+	// references 数组的最大长度
+	MaxReferences int
+
+	// 每个 affected 中的 versions 数组的最大长度
+	MaxVersions int
+
+	// JSON对象和数组的最大嵌套层数，最外层的对象算第1层
+	MaxDepth int
+
+	// 是否使用严格模式，遇到不认识的字段时返回 *UnknownFieldsError ，@see UnmarshalFromJsonStrict
+	Strict bool
+}
+
+// 超出限制的类型
+const (
+	LimitDocumentSize = "document size"
+	LimitAffected     = "affected"
+	LimitReferences   = "references"
+	LimitVersions     = "versions"
+	LimitDepth        = "depth"
+)
+
+// LimitExceededError 解码的文档超出了 DecodeOptions 中的限制
+type LimitExceededError struct {
+
+	// 超出的是哪个限制，比如 LimitAffected
+	Limit string
+
+	// 超出限制的位置，比如 affected[2].versions ，文档大小超出限制时为空
+	Path string
+
+	// 限制的值
+	Max int64
+}
+
+var _ error = &LimitExceededError{}
+
+func (x *LimitExceededError) Error() string {
+	switch x.Limit {
+	case LimitDocumentSize:
+		return fmt.Sprintf("document size exceeds the limit of %d bytes", x.Max)
+	case LimitDepth:
+		return fmt.Sprintf("%s: nesting depth exceeds the limit of %d", x.Path, x.Max)
+	default:
+		return fmt.Sprintf("%s: array length exceeds the limit of %d", x.Path, x.Max)
+	}
+}
+
+// Decode 从 io.Reader 中读取并解码一条JSON格式的漏洞记录，适合处理不可信的输入，比如用户上传的文件。
+// 超出 DecodeOptions 中的限制时返回 *LimitExceededError ，数组长度和嵌套层数会在反序列化之前检查，不会为超出限制的记录分配内存；
+// 文档本身会先被完整地读取到内存中，只有设置了 MaxDocumentSize 的时候读取的字节数才是有上限的，处理不可信的输入时应该总是设置它；
+// ctx被取消的时候返回 ctx.Err()
+func Decode[EcosystemSpecific, DatabaseSpecific any](ctx context.Context, reader io.Reader, options *DecodeOptions) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	if options == nil {
+		options = &DecodeOptions{}
+	}
+	reader = &contextReader{ctx: ctx, reader: reader}
+	if options.MaxDocumentSize > 0 {
+		reader = io.LimitReader(reader, options.MaxDocumentSize+1)
+	}
+	jsonBytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBytes[EcosystemSpecific, DatabaseSpecific](ctx, jsonBytes, options)
+}
+
+// 解码已经读取到内存中的记录，所有的读取方式（文件、压缩包、流、目录）都通过这里反序列化，保证限制和严格模式的行为一致
+func decodeBytes[EcosystemSpecific, DatabaseSpecific any](ctx context.Context, jsonBytes []byte, options *DecodeOptions) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	if options == nil {
+		options = &DecodeOptions{}
+	}
+	if options.MaxDocumentSize > 0 && int64(len(jsonBytes)) > options.MaxDocumentSize {
+		return nil, &LimitExceededError{Limit: LimitDocumentSize, Max: options.MaxDocumentSize}
+	}
+	if err := checkDecodeLimits(ctx, jsonBytes, options); err != nil {
+		return nil, err
+	}
+	if options.Strict {
+		return UnmarshalFromJsonStrict[EcosystemSpecific, DatabaseSpecific](jsonBytes)
+	}
+	return UnmarshalFromJson[EcosystemSpecific, DatabaseSpecific](jsonBytes)
+}
+
+// 每次读取之前检查ctx是否已经被取消
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (x *contextReader) Read(p []byte) (int, error) {
+	if err := x.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return x.reader.Read(p)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 解析JSON时正在读取的对象或者数组
+type decodeFrame struct {
+	array bool
+
+	// 在文档中的位置，比如 affected[2].versions
+	path string
+
+	// 数组中已经读取的元素数
+	count int
+
+	// 对象中下一个token是否是key，以及最近一次读取的key
+	expectKey bool
+	key       string
+}
+
+// 逐个token扫描文档，检查数组的长度和嵌套层数
+func checkDecodeLimits(ctx context.Context, jsonBytes []byte, options *DecodeOptions) error {
+	arrayLimits := map[string]int{}
+	if options.MaxAffected > 0 {
+		arrayLimits[LimitAffected] = options.MaxAffected
+	}
+	if options.MaxReferences > 0 {
+		arrayLimits[LimitReferences] = options.MaxReferences
+	}
+	if options.MaxVersions > 0 {
+		arrayLimits[LimitVersions] = options.MaxVersions
+	}
+	if len(arrayLimits) == 0 && options.MaxDepth <= 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	stack := make([]*decodeFrame, 0)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			// 语法错误留给反序列化的时候报告
+			return nil
+		}
+
+		// 当前token在文档中的位置
+		path := ""
+		if len(stack) != 0 {
+			parent := stack[len(stack)-1]
+			if parent.array {
+				if delim, ok := token.(json.Delim); !ok || (delim != ']' && delim != '}') {
+					parent.count++
+					if max, ok := arrayLimits[decodeArrayLimit(parent.path)]; ok && parent.count > max {
+						return &LimitExceededError{Limit: decodeArrayLimit(parent.path), Path: parent.path, Max: int64(max)}
+					}
+				}
+				path = fmt.Sprintf("%s[%d]", parent.path, parent.count-1)
+			} else {
+				if parent.expectKey {
+					if key, ok := token.(string); ok {
+						parent.key = key
+						parent.expectKey = false
+						continue
+					}
+				}
+				parent.expectKey = true
+				path = parent.key
+				if parent.path != "" {
+					path = parent.path + "." + parent.key
+				}
+			}
+		}
+
+		delim, ok := token.(json.Delim)
+		if !ok {
+			continue
+		}
+		switch delim {
+		case '{', '[':
+			if options.MaxDepth > 0 && len(stack)+1 > options.MaxDepth {
+				return &LimitExceededError{Limit: LimitDepth, Path: path, Max: int64(options.MaxDepth)}
+			}
+			stack = append(stack, &decodeFrame{array: delim == '[', path: path, expectKey: delim == '{'})
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		case '}', ']':
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// 数组对应的限制，只有顶层的 affected 、 references 和 affected 中的 versions 有限制，
+// 反序列化的时候字段名是不区分大小写的，所以这里也不区分大小写，比如 AFFECTED 也算 affected
+func decodeArrayLimit(path string) string {
+	path = strings.ToLower(path)
+	switch {
+	case path == "affected":
+		return LimitAffected
+	case path == "references":
+		return LimitReferences
+	case decodeAffectedVersionsPattern(path):
+		return LimitVersions
+	}
+	return ""
+}
+
+// 判断是否是 affected[n].versions
+func decodeAffectedVersionsPattern(path string) bool {
+	if !strings.HasPrefix(path, "affected[") || !strings.HasSuffix(path, "].versions") {
+		return false
+	}
+	index := strings.TrimSuffix(strings.TrimPrefix(path, "affected["), "].versions")
+	if index == "" {
+		return false
+	}
+	for _, c := range index {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,74 @@
+package osv_schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	document := `{
+		"id": "GHSA-1",
+		"affected": [
+			{"package": {"ecosystem": "npm", "name": "a"}, "versions": ["1.0.0", "1.0.1"]},
+			{"package": {"ecosystem": "npm", "name": "b"}, "versions": ["1.0.0", "1.0.1", "1.0.2"], "database_specific": {"versions": [1, 2, 3, 4]}}
+		],
+		"references": [{"type": "WEB", "url": "https://example.com"}]
+	}`
+
+	r, err := Decode[any, any](context.Background(), strings.NewReader(document), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "GHSA-1", r.ID)
+	assert.Len(t, r.Affected, 2)
+
+	_, err = Decode[any, any](context.Background(), strings.NewReader(document), &DecodeOptions{MaxAffected: 2, MaxReferences: 1, MaxVersions: 3, MaxDepth: 5})
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		options *DecodeOptions
+		want    *LimitExceededError
+	}{
+		{&DecodeOptions{MaxDocumentSize: 100}, &LimitExceededError{Limit: LimitDocumentSize, Max: 100}},
+		{&DecodeOptions{MaxAffected: 1}, &LimitExceededError{Limit: LimitAffected, Path: "affected", Max: 1}},
+		{&DecodeOptions{MaxReferences: 0, MaxVersions: 2}, &LimitExceededError{Limit: LimitVersions, Path: "affected[1].versions", Max: 2}},
+		{&DecodeOptions{MaxDepth: 3}, &LimitExceededError{Limit: LimitDepth, Path: "affected[0].package", Max: 3}},
+	}
+	for _, testCase := range testCases {
+		_, err := Decode[any, any](context.Background(), strings.NewReader(document), testCase.options)
+		var limitError *LimitExceededError
+		assert.True(t, errors.As(err, &limitError), "%v", err)
+		assert.Equal(t, testCase.want, limitError)
+	}
+
+	// 字段名的大小写不同也要受限制
+	mixedCase := `{"id": "GHSA-1", "AFFECTED": [{"Versions": ["1", "2"]}, {}, {}], "References": [{}, {}]}`
+	mixedCaseTestCases := []struct {
+		options *DecodeOptions
+		want    *LimitExceededError
+	}{
+		{&DecodeOptions{MaxAffected: 1}, &LimitExceededError{Limit: LimitAffected, Path: "AFFECTED", Max: 1}},
+		{&DecodeOptions{MaxReferences: 1}, &LimitExceededError{Limit: LimitReferences, Path: "References", Max: 1}},
+		{&DecodeOptions{MaxVersions: 1}, &LimitExceededError{Limit: LimitVersions, Path: "AFFECTED[0].Versions", Max: 1}},
+	}
+	for _, testCase := range mixedCaseTestCases {
+		_, err := Decode[any, any](context.Background(), strings.NewReader(mixedCase), testCase.options)
+		var limitError *LimitExceededError
+		assert.True(t, errors.As(err, &limitError), "%v", err)
+		assert.Equal(t, testCase.want, limitError)
+	}
+
+	_, err = Decode[any, any](context.Background(), strings.NewReader(`{"id": "GHSA-1", "foo": 1}`), &DecodeOptions{Strict: true})
+	var unknownFieldsError *UnknownFieldsError
+	assert.True(t, errors.As(err, &unknownFieldsError))
+
+	_, err = Decode[any, any](context.Background(), strings.NewReader(`{"id": `), &DecodeOptions{MaxDepth: 2})
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Decode[any, any](ctx, strings.NewReader(document), nil)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,483 @@
+package osv_schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// RecordDiff 同一条漏洞记录的两个版本之间的字段级别的差异
+type RecordDiff struct {
+	ID string `json:"id"`
+
+	AliasesAdded   []string `json:"aliases_added,omitempty"`
+	AliasesRemoved []string `json:"aliases_removed,omitempty"`
+
+	ReferencesAdded   []*Reference `json:"references_added,omitempty"`
+	ReferencesRemoved []*Reference `json:"references_removed,omitempty"`
+
+	// 新增和删除的受影响的包
+	AffectedAdded   []*Package `json:"affected_added,omitempty"`
+	AffectedRemoved []*Package `json:"affected_removed,omitempty"`
+
+	// 两个版本中都有的包的变化
+	AffectedChanged []*AffectedDiff `json:"affected_changed,omitempty"`
+
+	// 漏洞本身的严重级别的变化
+	SeverityChanges []*SeverityChange `json:"severity_changes,omitempty"`
+
+	SummaryChange *TextChange `json:"summary_change,omitempty"`
+	DetailsChange *TextChange `json:"details_change,omitempty"`
+}
+
+// AffectedDiff 同一个受影响的包的变化
+type AffectedDiff struct {
+	Package *Package `json:"package"`
+
+	EventsAdded   []*RangeEvent `json:"events_added,omitempty"`
+	EventsRemoved []*RangeEvent `json:"events_removed,omitempty"`
+
+	VersionsAdded   []string `json:"versions_added,omitempty"`
+	VersionsRemoved []string `json:"versions_removed,omitempty"`
+
+	SeverityChanges []*SeverityChange `json:"severity_changes,omitempty"`
+}
+
+// RangeEvent 某个范围中的一个事件
+type RangeEvent struct {
+	Type  RangeType `json:"type"`
+	Repo  string    `json:"repo,omitempty"`
+	Event *Event    `json:"event"`
+}
+
+// SeverityChange 某种类型的严重级别的变化，Before为空表示新增，After为空表示删除
+type SeverityChange struct {
+	Type   SeverityType `json:"type"`
+	Before string       `json:"before,omitempty"`
+	After  string       `json:"after,omitempty"`
+}
+
+// TextChange 文本字段的变化
+type TextChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// Diff 比较记录的两个版本，x是旧的版本，after是新的版本
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) Diff(after *OsvSchema[EcosystemSpecific, DatabaseSpecific]) *RecordDiff {
+	diff := &RecordDiff{ID: after.ID}
+	diff.AliasesAdded, diff.AliasesRemoved = diffStrings(x.Aliases, after.Aliases)
+	diff.ReferencesAdded, diff.ReferencesRemoved = diffReferences(x.References, after.References)
+	diff.SeverityChanges = diffSeverity(x.Severity, after.Severity)
+	if x.Summary != after.Summary {
+		diff.SummaryChange = &TextChange{Before: x.Summary, After: after.Summary}
+	}
+	if x.Details != after.Details {
+		diff.DetailsChange = &TextChange{Before: x.Details, After: after.Details}
+	}
+
+	// 同一个包可能有多个 affected ，比如不同的范围分开写，合并之后再比较
+	beforeKeys, beforeAffected := groupAffectedByDiffKey(x.Affected)
+	afterKeys, afterAffected := groupAffectedByDiffKey(after.Affected)
+	for _, key := range afterKeys {
+		before, exists := beforeAffected[key]
+		if !exists {
+			diff.AffectedAdded = append(diff.AffectedAdded, afterAffected[key].Package)
+			continue
+		}
+		if affectedDiff := diffAffected(before, afterAffected[key]); affectedDiff != nil {
+			diff.AffectedChanged = append(diff.AffectedChanged, affectedDiff)
+		}
+	}
+	for _, key := range beforeKeys {
+		if _, exists := afterAffected[key]; !exists {
+			diff.AffectedRemoved = append(diff.AffectedRemoved, beforeAffected[key].Package)
+		}
+	}
+	return diff
+}
+
+// IsEmpty 判断是否没有任何差异
+func (x *RecordDiff) IsEmpty() bool {
+	return len(x.AliasesAdded) == 0 && len(x.AliasesRemoved) == 0 &&
+		len(x.ReferencesAdded) == 0 && len(x.ReferencesRemoved) == 0 &&
+		len(x.AffectedAdded) == 0 && len(x.AffectedRemoved) == 0 && len(x.AffectedChanged) == 0 &&
+		len(x.SeverityChanges) == 0 && x.SummaryChange == nil && x.DetailsChange == nil
+}
+
+// Descriptions 返回人能读懂的差异描述，每个变化一句，比如 fixed version 4.17.21 added for lodash
+func (x *RecordDiff) Descriptions() []string {
+	descriptions := make([]string, 0)
+	for _, alias := range x.AliasesAdded {
+		descriptions = append(descriptions, fmt.Sprintf("alias %s added", alias))
+	}
+	for _, alias := range x.AliasesRemoved {
+		descriptions = append(descriptions, fmt.Sprintf("alias %s removed", alias))
+	}
+	for _, reference := range x.ReferencesAdded {
+		descriptions = append(descriptions, fmt.Sprintf("reference %s added", reference.URL))
+	}
+	for _, reference := range x.ReferencesRemoved {
+		descriptions = append(descriptions, fmt.Sprintf("reference %s removed", reference.URL))
+	}
+	for _, change := range x.SeverityChanges {
+		descriptions = append(descriptions, change.description(""))
+	}
+	for _, p := range x.AffectedAdded {
+		descriptions = append(descriptions, fmt.Sprintf("affected package %s added", packageDiffName(p)))
+	}
+	for _, p := range x.AffectedRemoved {
+		descriptions = append(descriptions, fmt.Sprintf("affected package %s removed", packageDiffName(p)))
+	}
+	for _, affected := range x.AffectedChanged {
+		name := packageDiffName(affected.Package)
+		for _, rangeEvent := range affected.EventsAdded {
+			descriptions = append(descriptions, fmt.Sprintf("%s version %s added for %s", eventDiffKind(rangeEvent.Event), rangeEvent.Event.version(), name))
+		}
+		for _, rangeEvent := range affected.EventsRemoved {
+			descriptions = append(descriptions, fmt.Sprintf("%s version %s removed for %s", eventDiffKind(rangeEvent.Event), rangeEvent.Event.version(), name))
+		}
+		if len(affected.VersionsAdded) != 0 {
+			descriptions = append(descriptions, fmt.Sprintf("%d affected versions added for %s", len(affected.VersionsAdded), name))
+		}
+		if len(affected.VersionsRemoved) != 0 {
+			descriptions = append(descriptions, fmt.Sprintf("%d affected versions removed for %s", len(affected.VersionsRemoved), name))
+		}
+		for _, change := range affected.SeverityChanges {
+			descriptions = append(descriptions, change.description(name))
+		}
+	}
+	if x.SummaryChange != nil {
+		descriptions = append(descriptions, "summary changed")
+	}
+	if x.DetailsChange != nil {
+		descriptions = append(descriptions, "details changed")
+	}
+	return descriptions
+}
+
+func (x *SeverityChange) description(packageName string) string {
+	var description string
+	switch {
+	case x.Before == "":
+		description = fmt.Sprintf("severity %s %s added", x.Type, x.After)
+	case x.After == "":
+		description = fmt.Sprintf("severity %s %s removed", x.Type, x.Before)
+	default:
+		description = fmt.Sprintf("severity %s changed from %s to %s", x.Type, x.Before, x.After)
+	}
+	if packageName != "" {
+		description += " for " + packageName
+	}
+	return description
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+func diffAffected[EcosystemSpecific, DatabaseSpecific any](before, after *Affected[EcosystemSpecific, DatabaseSpecific]) *AffectedDiff {
+	diff := &AffectedDiff{Package: after.Package}
+	beforeEvents, afterEvents := collectRangeEvents(before.Ranges), collectRangeEvents(after.Ranges)
+	for _, rangeEvent := range afterEvents {
+		if !containsRangeEvent(beforeEvents, rangeEvent) {
+			diff.EventsAdded = append(diff.EventsAdded, rangeEvent)
+		}
+	}
+	for _, rangeEvent := range beforeEvents {
+		if !containsRangeEvent(afterEvents, rangeEvent) {
+			diff.EventsRemoved = append(diff.EventsRemoved, rangeEvent)
+		}
+	}
+	diff.VersionsAdded, diff.VersionsRemoved = diffStrings(before.Versions, after.Versions)
+	diff.SeverityChanges = diffSeverity(before.Severity, after.Severity)
+	if len(diff.EventsAdded) == 0 && len(diff.EventsRemoved) == 0 && len(diff.VersionsAdded) == 0 &&
+		len(diff.VersionsRemoved) == 0 && len(diff.SeverityChanges) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// 按照包把 affected 分组，同一个包的多个 affected 的范围、版本和严重级别合并到一起，返回按照第一次出现的顺序排列的key
+func groupAffectedByDiffKey[EcosystemSpecific, DatabaseSpecific any](affectedSlice AffectedSlice[EcosystemSpecific, DatabaseSpecific]) ([]string, map[string]*Affected[EcosystemSpecific, DatabaseSpecific]) {
+	keys := make([]string, 0)
+	groups := make(map[string]*Affected[EcosystemSpecific, DatabaseSpecific])
+	for _, affected := range affectedSlice {
+		if affected == nil {
+			continue
+		}
+		key := affectedDiffKey(affected.Package)
+		group, exists := groups[key]
+		if !exists {
+			keys = append(keys, key)
+			groups[key] = affected
+			continue
+		}
+		groups[key] = &Affected[EcosystemSpecific, DatabaseSpecific]{
+			Package:  group.Package,
+			Ranges:   append(append([]*Range[DatabaseSpecific]{}, group.Ranges...), affected.Ranges...),
+			Versions: append(append([]string{}, group.Versions...), affected.Versions...),
+			Severity: append(append([]*Severity{}, group.Severity...), affected.Severity...),
+		}
+	}
+	return keys, groups
+}
+
+func collectRangeEvents[DatabaseSpecific any](ranges []*Range[DatabaseSpecific]) []*RangeEvent {
+	rangeEvents := make([]*RangeEvent, 0)
+	for _, r := range ranges {
+		if r == nil {
+			continue
+		}
+		for _, event := range r.Events {
+			if event == nil {
+				continue
+			}
+			rangeEvents = append(rangeEvents, &RangeEvent{Type: r.Type, Repo: r.Repo, Event: event})
+		}
+	}
+	return rangeEvents
+}
+
+func containsRangeEvent(rangeEvents []*RangeEvent, target *RangeEvent) bool {
+	for _, rangeEvent := range rangeEvents {
+		if rangeEvent.Type == target.Type && rangeEvent.Repo == target.Repo && eventKind(rangeEvent.Event) == eventKind(target.Event) &&
+			rangeEvent.Event.version() == target.Event.version() {
+			return true
+		}
+	}
+	return false
+}
+
+func eventDiffKind(event *Event) string {
+	return strings.ReplaceAll(eventKind(event), "_", " ")
+}
+
+func diffStrings(before, after []string) (added, removed []string) {
+	beforeSet := make(map[string]bool, len(before))
+	for _, s := range before {
+		beforeSet[s] = true
+	}
+	afterSet := make(map[string]bool, len(after))
+	for _, s := range after {
+		if !beforeSet[s] && !afterSet[s] {
+			added = append(added, s)
+		}
+		afterSet[s] = true
+	}
+	for _, s := range before {
+		if !afterSet[s] {
+			removed = append(removed, s)
+			afterSet[s] = true
+		}
+	}
+	return added, removed
+}
+
+func diffReferences(before, after References) (added, removed []*Reference) {
+	contains := func(references References, target *Reference) bool {
+		for _, reference := range references {
+			if reference != nil && reference.Type == target.Type && reference.URL == target.URL {
+				return true
+			}
+		}
+		return false
+	}
+	for _, reference := range after {
+		if reference == nil {
+			continue
+		}
+		if !contains(before, reference) && !contains(added, reference) {
+			added = append(added, reference)
+		}
+	}
+	for _, reference := range before {
+		if reference == nil {
+			continue
+		}
+		if !contains(after, reference) && !contains(removed, reference) {
+			removed = append(removed, reference)
+		}
+	}
+	return added, removed
+}
+
+// 按照类型比较严重级别，同一种类型可以有多个分数，删除的分数和新增的分数按照顺序配对为修改
+func diffSeverity(before, after []*Severity) []*SeverityChange {
+	types := make([]SeverityType, 0)
+	beforeScores := make(map[SeverityType][]string)
+	afterScores := make(map[SeverityType][]string)
+	collect := func(severities []*Severity, scores map[SeverityType][]string) {
+		for _, severity := range severities {
+			if severity == nil {
+				continue
+			}
+			if _, exists := beforeScores[severity.Type]; !exists {
+				if _, exists := afterScores[severity.Type]; !exists {
+					types = append(types, severity.Type)
+				}
+			}
+			scores[severity.Type] = append(scores[severity.Type], severity.Score)
+		}
+	}
+	collect(after, afterScores)
+	collect(before, beforeScores)
+
+	changes := make([]*SeverityChange, 0)
+	for _, severityType := range types {
+		added, removed := diffStrings(beforeScores[severityType], afterScores[severityType])
+		for i := 0; i < len(added) || i < len(removed); i++ {
+			change := &SeverityChange{Type: severityType}
+			if i < len(removed) {
+				change.Before = removed[i]
+			}
+			if i < len(added) {
+				change.After = added[i]
+			}
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+func affectedDiffKey(p *Package) string {
+	if p == nil {
+		return ""
+	}
+	if p.Ecosystem == "" && p.Name == "" {
+		return p.PackageUrl
+	}
+	return string(p.Ecosystem) + "\x00" + p.Name
+}
+
+func packageDiffName(p *Package) string {
+	if p == nil {
+		return "<unknown package>"
+	}
+	if p.Name == "" {
+		return p.PackageUrl
+	}
+	return p.Name
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// JsonPatchOperation RFC 6902 JSON Patch中的一个操作
+type JsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JsonPatch 返回把记录从x变成after的RFC 6902 JSON Patch，基于序列化之后的JSON计算，所以不认识的字段也会参与比较。
+// 对象中的字段按照名字的顺序比较，数组按照下标逐个比较，多出来的元素在末尾添加或者从后往前删除
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) JsonPatch(after *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ([]*JsonPatchOperation, error) {
+	beforeValue, err := marshalToJsonValue(x)
+	if err != nil {
+		return nil, err
+	}
+	afterValue, err := marshalToJsonValue(after)
+	if err != nil {
+		return nil, err
+	}
+	operations := make([]*JsonPatchOperation, 0)
+	return appendJsonPatch(operations, "", beforeValue, afterValue)
+}
+
+func marshalToJsonValue(v any) (any, error) {
+	jsonBytes, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func appendJsonPatch(operations []*JsonPatchOperation, path string, before, after any) ([]*JsonPatchOperation, error) {
+	switch beforeValue := before.(type) {
+	case map[string]any:
+		afterValue, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(beforeValue)+len(afterValue))
+		for key := range beforeValue {
+			keys = append(keys, key)
+		}
+		for key := range afterValue {
+			if _, exists := beforeValue[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := path + "/" + escapeJsonPointer(key)
+			beforeChild, inBefore := beforeValue[key]
+			afterChild, inAfter := afterValue[key]
+			var err error
+			switch {
+			case !inAfter:
+				operations = append(operations, &JsonPatchOperation{Op: "remove", Path: childPath})
+			case !inBefore:
+				operations, err = appendJsonPatchValue(operations, "add", childPath, afterChild)
+			default:
+				operations, err = appendJsonPatch(operations, childPath, beforeChild, afterChild)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		return operations, nil
+	case []any:
+		afterValue, ok := after.([]any)
+		if !ok {
+			break
+		}
+		var err error
+		for i := 0; i < len(beforeValue) && i < len(afterValue); i++ {
+			if operations, err = appendJsonPatch(operations, path+"/"+strconv.Itoa(i), beforeValue[i], afterValue[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i := len(beforeValue); i < len(afterValue); i++ {
+			if operations, err = appendJsonPatchValue(operations, "add", path+"/"+strconv.Itoa(i), afterValue[i]); err != nil {
+				return nil, err
+			}
+		}
+		for i := len(beforeValue) - 1; i >= len(afterValue); i-- {
+			operations = append(operations, &JsonPatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+		}
+		return operations, nil
+	}
+	if reflect.DeepEqual(before, after) {
+		return operations, nil
+	}
+	return appendJsonPatchValue(operations, "replace", path, after)
+}
+
+func appendJsonPatchValue(operations []*JsonPatchOperation, op, path string, value any) ([]*JsonPatchOperation, error) {
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append(operations, &JsonPatchOperation{Op: op, Path: path, Value: valueBytes}), nil
+}
+
+// RFC 6901 JSON Pointer的转义
+func escapeJsonPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
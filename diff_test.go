@@ -0,0 +1,139 @@
+package osv_schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordDiff(t *testing.T) {
+	before, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"aliases": ["CVE-2024-1"],
+		"summary": "Prototype pollution",
+		"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N"}],
+		"affected": [
+			{"package": {"ecosystem": "npm", "name": "lodash"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]},
+			{"package": {"ecosystem": "npm", "name": "lodash-es"}, "versions": ["1.0.0"]}
+		],
+		"references": [{"type": "WEB", "url": "https://example.com/a"}]
+	}`))
+	assert.Nil(t, err)
+	after, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"aliases": ["CVE-2024-1", "CVE-2024-2"],
+		"summary": "Prototype pollution in lodash",
+		"severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N"}],
+		"affected": [
+			{"package": {"ecosystem": "npm", "name": "lodash"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]},
+			{"package": {"ecosystem": "npm", "name": "lodash.merge"}}
+		],
+		"references": [{"type": "WEB", "url": "https://example.com/a"}, {"type": "FIX", "url": "https://example.com/fix"}]
+	}`))
+	assert.Nil(t, err)
+
+	assert.True(t, before.Diff(before).IsEmpty())
+	diff := before.Diff(after)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{"CVE-2024-2"}, diff.AliasesAdded)
+	assert.Empty(t, diff.AliasesRemoved)
+	assert.Len(t, diff.ReferencesAdded, 1)
+	assert.Equal(t, "lodash.merge", diff.AffectedAdded[0].Name)
+	assert.Equal(t, "lodash-es", diff.AffectedRemoved[0].Name)
+	assert.Len(t, diff.AffectedChanged, 1)
+	assert.Equal(t, "4.17.21", diff.AffectedChanged[0].EventsAdded[0].Event.Fixed)
+	assert.Equal(t, &TextChange{Before: "Prototype pollution", After: "Prototype pollution in lodash"}, diff.SummaryChange)
+	assert.Nil(t, diff.DetailsChange)
+	assert.Equal(t, []string{
+		"alias CVE-2024-2 added",
+		"reference https://example.com/fix added",
+		"severity CVSS_V3 changed from CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N to CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:N",
+		"affected package lodash.merge added",
+		"affected package lodash-es removed",
+		"fixed version 4.17.21 added for lodash",
+		"summary changed",
+	}, diff.Descriptions())
+
+	_, err = json.Marshal(diff)
+	assert.Nil(t, err)
+}
+
+func TestRecordDiff_NilEntries(t *testing.T) {
+	before, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"severity": [null, {"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:N"}],
+		"affected": [null, {"ranges": [null, {"type": "SEMVER", "events": [null, {"introduced": "0"}]}]}],
+		"references": [null]
+	}`))
+	assert.Nil(t, err)
+	after, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"severity": [null],
+		"affected": [{"package": {"ecosystem": "npm", "name": "lodash"}}, null],
+		"references": [null, {"type": "WEB", "url": "https://example.com/a"}]
+	}`))
+	assert.Nil(t, err)
+
+	assert.True(t, before.Diff(before).IsEmpty())
+	diff := before.Diff(after)
+	assert.Len(t, diff.SeverityChanges, 1)
+	assert.Len(t, diff.ReferencesAdded, 1)
+	assert.Equal(t, "lodash", diff.AffectedAdded[0].Name)
+	assert.Len(t, diff.AffectedRemoved, 1)
+	assert.NotEmpty(t, diff.Descriptions())
+}
+
+func TestRecordDiff_SamePackage(t *testing.T) {
+	before, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"severity": [{"type": "CVSS_V3", "score": "a"}, {"type": "CVSS_V3", "score": "b"}],
+		"affected": [
+			{"package": {"ecosystem": "npm", "name": "a"}, "versions": ["1.0.0"]},
+			{"package": {"ecosystem": "npm", "name": "a"}, "versions": ["2.0.0"]}
+		]
+	}`))
+	assert.Nil(t, err)
+	after, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "GHSA-1",
+		"severity": [{"type": "CVSS_V3", "score": "a"}, {"type": "CVSS_V3", "score": "c"}],
+		"affected": [
+			{"package": {"ecosystem": "npm", "name": "a"}, "versions": ["1.0.0"]},
+			{"package": {"ecosystem": "npm", "name": "a"}, "versions": ["2.0.1"]}
+		]
+	}`))
+	assert.Nil(t, err)
+
+	assert.True(t, before.Diff(before).IsEmpty())
+	diff := before.Diff(after)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []*SeverityChange{{Type: SeverityTypeCVSS3, Before: "b", After: "c"}}, diff.SeverityChanges)
+	assert.Empty(t, diff.AffectedAdded)
+	assert.Empty(t, diff.AffectedRemoved)
+	assert.Len(t, diff.AffectedChanged, 1)
+	assert.Equal(t, []string{"2.0.1"}, diff.AffectedChanged[0].VersionsAdded)
+	assert.Equal(t, []string{"2.0.0"}, diff.AffectedChanged[0].VersionsRemoved)
+}
+
+func TestJsonPatch(t *testing.T) {
+	before, err := UnmarshalFromJson[any, any]([]byte(`{"id": "GHSA-1", "aliases": ["CVE-1", "CVE-2"], "summary": "a", "x_a/b": 1}`))
+	assert.Nil(t, err)
+	after, err := UnmarshalFromJson[any, any]([]byte(`{"id": "GHSA-1", "aliases": ["CVE-1"], "summary": "b", "details": "d", "x_new": null}`))
+	assert.Nil(t, err)
+
+	operations, err := before.JsonPatch(after)
+	assert.Nil(t, err)
+	patch, err := json.Marshal(operations)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[
+		{"op": "remove", "path": "/aliases/1"},
+		{"op": "replace", "path": "/details", "value": "d"},
+		{"op": "replace", "path": "/summary", "value": "b"},
+		{"op": "remove", "path": "/x_a~1b"},
+		{"op": "add", "path": "/x_new", "value": null}
+	]`, string(patch))
+
+	operations, err = before.JsonPatch(before)
+	assert.Nil(t, err)
+	assert.Empty(t, operations)
+}
@@ -0,0 +1,217 @@
+package osv_schema
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 按照文件扩展名把文件内容转换为JSON，再按照JSON反序列化
+var recordFileFormats = map[string]func(fileBytes []byte) ([]byte, error){
+	".json": func(fileBytes []byte) ([]byte, error) {
+		return fileBytes, nil
+	},
+	".yaml": yamlToJson,
+	".yml":  yamlToJson,
+}
+
+// 获取文件对应的格式，压缩格式的扩展名会被去掉，比如 .json.gz 按照 .json 处理，不认识的扩展名返回nil
+func getRecordFileFormat(filePath string) func(fileBytes []byte) ([]byte, error) {
+	return recordFileFormats[strings.ToLower(filepath.Ext(trimCompressionExtension(filePath)))]
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// DirectoryLoadOptions 加载目录的选项，传nil的话使用默认选项
+type DirectoryLoadOptions struct {
+
+	// 要加载的文件，相对于根目录、以 / 分隔的glob，支持 ** 匹配任意层目录，比如 advisories/**/*.json 。
+	// 不包含 / 的模式匹配任意目录下的文件名，比如 GHSA-*.json 。为空的时候加载所有认识的格式的文件
+	Include []string
+
+	// 要排除的文件或者目录，语法和 Include 一样，匹配到的目录不会再往下遍历，比如 .git
+	Exclude []string
+
+	// 并行解析的文件数，默认为CPU的核数
+	Concurrency int
+
+	// 是否使用严格模式，遇到不认识的字段时返回 *UnknownFieldsError ，@see UnmarshalFromJsonStrict
+	Strict bool
+}
+
+// DirectoryLoadError 加载目录时有文件加载失败了，包含每个失败的文件的错误，按照路径排列
+type DirectoryLoadError struct {
+	Errors []*FileError
+}
+
+var _ error = &DirectoryLoadError{}
+
+func (x *DirectoryLoadError) Error() string {
+	if len(x.Errors) == 1 {
+		return x.Errors[0].Error()
+	}
+	return fmt.Sprintf("%d files failed to load, the first one is %s", len(x.Errors), x.Errors[0].Error())
+}
+
+// LoadDirectory 并发地加载目录下的所有漏洞记录，返回按照路径排列的记录。
+// 部分文件加载失败的时候仍然会返回其它加载成功的记录，同时返回 *DirectoryLoadError ；ctx被取消的时候返回已经加载的记录和 ctx.Err()
+func LoadDirectory[EcosystemSpecific, DatabaseSpecific any](ctx context.Context, root string, options *DirectoryLoadOptions) ([]*FileRecord[EcosystemSpecific, DatabaseSpecific], error) {
+	if options == nil {
+		options = &DirectoryLoadOptions{}
+	}
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	var lock sync.Mutex
+	records := make([]*FileRecord[EcosystemSpecific, DatabaseSpecific], 0)
+	fileErrors := make([]*FileError, 0)
+	paths := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filePath := range paths {
+				if ctx.Err() != nil {
+					continue
+				}
+				r, err := loadRecordFile[EcosystemSpecific, DatabaseSpecific](ctx, filePath, options.Strict)
+				lock.Lock()
+				if err != nil {
+					fileErrors = append(fileErrors, &FileError{Path: filePath, Err: err})
+				} else {
+					records = append(records, &FileRecord[EcosystemSpecific, DatabaseSpecific]{Path: filePath, Record: r})
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	walkErr := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			lock.Lock()
+			fileErrors = append(fileErrors, &FileError{Path: filePath, Err: err})
+			lock.Unlock()
+			if entry != nil && entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel != "." && matchAnyGlob(options.Exclude, rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if getRecordFileFormat(filePath) == nil || matchAnyGlob(options.Exclude, rel) {
+			return nil
+		}
+		if len(options.Include) != 0 && !matchAnyGlob(options.Include, rel) {
+			return nil
+		}
+		select {
+		case paths <- filePath:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	close(paths)
+	wg.Wait()
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Path < records[j].Path
+	})
+	if err := ctx.Err(); err != nil {
+		return records, err
+	}
+	if walkErr != nil {
+		return records, walkErr
+	}
+	if len(fileErrors) != 0 {
+		sort.Slice(fileErrors, func(i, j int) bool {
+			return fileErrors[i].Path < fileErrors[j].Path
+		})
+		return records, &DirectoryLoadError{Errors: fileErrors}
+	}
+	return records, nil
+}
+
+// 按照扩展名读取并解析一个记录文件
+func loadRecordFile[EcosystemSpecific, DatabaseSpecific any](ctx context.Context, filePath string, strict bool) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	format := getRecordFileFormat(filePath)
+	if format == nil {
+		return nil, fmt.Errorf("unsupported file format %q", filepath.Ext(filePath))
+	}
+	fileBytes, err := readFileDecompressed(filePath)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err := format(fileBytes)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBytes[EcosystemSpecific, DatabaseSpecific](ctx, jsonBytes, &DecodeOptions{Strict: strict})
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+func matchAnyGlob(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob 判断以 / 分隔的相对路径是否匹配glob，** 匹配任意层目录（包括0层），不包含 / 的模式只匹配文件名
+func matchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(patterns, names []string) bool {
+	for len(patterns) != 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchGlobSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(patterns[0], names[0]); !ok {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,67 @@
+package osv_schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		assert.Nil(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"advisories/github-reviewed/2023/GHSA-1.json": `{"id": "GHSA-1"}`,
+		"advisories/github-reviewed/2024/GHSA-2.json": `{"id": "GHSA-2"}`,
+		"advisories/unreviewed/GHSA-3.json":           `{"id": "GHSA-3"}`,
+		"advisories/github-reviewed/bad.json":         `{"id": `,
+		".git/objects/foo.json":                       `{}`,
+		"advisories/pypa/PYSEC-4.yaml":                "id: PYSEC-4\nmodified: 2021-07-22T00:00:00Z\n",
+		"README.md":                                   "# advisories",
+	})
+
+	records, err := LoadDirectory[any, any](context.Background(), root, &DirectoryLoadOptions{
+		Include:     []string{"advisories/github-reviewed/**/*.json"},
+		Exclude:     []string{".git"},
+		Concurrency: 2,
+	})
+	var loadError *DirectoryLoadError
+	assert.True(t, errors.As(err, &loadError))
+	assert.Len(t, loadError.Errors, 1)
+	assert.Equal(t, filepath.Join(root, "advisories", "github-reviewed", "bad.json"), loadError.Errors[0].Path)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "GHSA-1", records[0].Record.ID)
+	assert.Equal(t, "GHSA-2", records[1].Record.ID)
+
+	records, err = LoadDirectory[any, any](context.Background(), root, &DirectoryLoadOptions{Include: []string{"GHSA-*.json"}})
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+
+	records, err = LoadDirectory[any, any](context.Background(), root, &DirectoryLoadOptions{Include: []string{"**/*.yaml"}})
+	assert.Nil(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "PYSEC-4", records[0].Record.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = LoadDirectory[any, any](ctx, root, nil)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestMatchGlob(t *testing.T) {
+	assert.True(t, matchGlob("**/*.json", "a.json"))
+	assert.True(t, matchGlob("a/**/b/*.json", "a/x/y/b/c.json"))
+	assert.True(t, matchGlob("a/**", "a/b/c"))
+	assert.False(t, matchGlob("a/*.json", "a/b/c.json"))
+	assert.True(t, matchGlob("*.yaml", "x/y/z.yaml"))
+}
@@ -0,0 +1,345 @@
+package osv_schema
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// DirectoryLayout 决定一条记录写到目录下的哪些文件中，返回相对于根目录、以 / 分隔的路径，
+// 一条记录可以写到多个位置，比如影响了多个包的记录在每个包的目录下都写一份
+type DirectoryLayout[EcosystemSpecific, DatabaseSpecific any] func(record *OsvSchema[EcosystemSpecific, DatabaseSpecific]) []string
+
+// EcosystemLayout 和osv.dev的导出一样按照ecosystem分目录， <ecosystem>/<ID>.json ，目录使用去掉 :<RELEASE> 后缀的ecosystem，
+// 比如 Debian:11 的记录放在 Debian 目录下，没有 affected 的记录放在根目录下
+func EcosystemLayout[EcosystemSpecific, DatabaseSpecific any](record *OsvSchema[EcosystemSpecific, DatabaseSpecific]) []string {
+	paths := make([]string, 0)
+	for _, affected := range record.Affected {
+		if affected == nil || affected.Package == nil || affected.Package.Ecosystem == "" {
+			continue
+		}
+		paths = appendIfMissing(paths, path.Join(escapePathSegment(string(affected.Package.Ecosystem.Base())), record.ID+".json"))
+	}
+	if len(paths) == 0 {
+		paths = append(paths, record.ID+".json")
+	}
+	return paths
+}
+
+// EcosystemPackageLayout 按照ecosystem和包名分目录， <ecosystem>/<package>/<ID>.json ，ecosystem和 EcosystemLayout 一样去掉 :<RELEASE> 后缀。
+// 包名中的 / 会成为子目录，比如npm的 @scope/name ；文件名中不能使用的字符会被转义为 %XX ，比如Maven的 group:artifact 为 group%3Aartifact ，
+// 包名是链接之类的会产生空目录或者跳出目录的名字时，整个包名作为一级目录转义，比如 https://github.com/a/b 为 https%3A%2F%2Fgithub.com%2Fa%2Fb
+func EcosystemPackageLayout[EcosystemSpecific, DatabaseSpecific any](record *OsvSchema[EcosystemSpecific, DatabaseSpecific]) []string {
+	paths := make([]string, 0)
+	for _, affected := range record.Affected {
+		if affected == nil || affected.Package == nil || affected.Package.Ecosystem == "" || affected.Package.Name == "" {
+			continue
+		}
+		paths = appendIfMissing(paths, path.Join(escapePathSegment(string(affected.Package.Ecosystem.Base())), packagePath(affected.Package.Name), record.ID+".json"))
+	}
+	if len(paths) == 0 {
+		paths = append(paths, record.ID+".json")
+	}
+	return paths
+}
+
+// IDPrefixLayout 按照编号的前缀分目录， <ID-prefix>/<ID>.json ，比如 GHSA/GHSA-xxxx-xxxx-xxxx.json ，没有前缀的编号放在根目录下
+func IDPrefixLayout[EcosystemSpecific, DatabaseSpecific any](record *OsvSchema[EcosystemSpecific, DatabaseSpecific]) []string {
+	if index := strings.Index(record.ID, "-"); index > 0 {
+		return []string{path.Join(record.ID[:index], record.ID+".json")}
+	}
+	return []string{record.ID + ".json"}
+}
+
+// 包名对应的相对路径，@see EcosystemPackageLayout
+func packagePath(name string) string {
+	segments := strings.Split(name, "/")
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			return escapePathSegment(name)
+		}
+	}
+	for i, segment := range segments {
+		segments[i] = escapePathSegment(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// 把路径中的一段里不能出现在文件名中的字符转义为 %XX ， % 本身也会被转义，保证不同的名字不会转义为同一个路径
+func escapePathSegment(segment string) string {
+	if segment == "." || segment == ".." {
+		return strings.ReplaceAll(segment, ".", "%2E")
+	}
+	builder := strings.Builder{}
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if c < 0x20 || c == 0x7f || strings.IndexByte(`<>:"/\|?*%`, c) >= 0 {
+			builder.WriteString(fmt.Sprintf("%%%02X", c))
+		} else {
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+func appendIfMissing(paths []string, p string) []string {
+	for _, exists := range paths {
+		if exists == p {
+			return paths
+		}
+	}
+	return append(paths, p)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// DirectoryWriterOptions 写目录的选项，传nil的话使用默认选项
+type DirectoryWriterOptions[EcosystemSpecific, DatabaseSpecific any] struct {
+
+	// 记录文件的布局，默认为 EcosystemLayout
+	Layout DirectoryLayout[EcosystemSpecific, DatabaseSpecific]
+}
+
+// DirectoryWriter 把漏洞记录按照布局写到目录中，用来发布自己的OSV数据库。
+// 所有的文件都先写到同一目录下的临时文件再重命名，读取的一方不会看到写了一半的文件；
+// 相同的记录总是输出相同的字节，字段按照结构体中的顺序，不认识的字段按照名字排序，缩进为两个空格
+type DirectoryWriter[EcosystemSpecific, DatabaseSpecific any] struct {
+	root   string
+	layout DirectoryLayout[EcosystemSpecific, DatabaseSpecific]
+}
+
+// NewDirectoryWriter 创建一个写到root目录下的 DirectoryWriter ，目录不存在的时候会在写入时创建
+func NewDirectoryWriter[EcosystemSpecific, DatabaseSpecific any](root string, options *DirectoryWriterOptions[EcosystemSpecific, DatabaseSpecific]) *DirectoryWriter[EcosystemSpecific, DatabaseSpecific] {
+	x := &DirectoryWriter[EcosystemSpecific, DatabaseSpecific]{root: root, layout: EcosystemLayout[EcosystemSpecific, DatabaseSpecific]}
+	if options != nil && options.Layout != nil {
+		x.layout = options.Layout
+	}
+	return x
+}
+
+// Paths 返回记录按照布局应该写到的文件的相对路径
+func (x *DirectoryWriter[EcosystemSpecific, DatabaseSpecific]) Paths(record *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ([]string, error) {
+	if record.ID == "" || strings.ContainsAny(record.ID, `/\`) || record.ID == "." || record.ID == ".." {
+		return nil, fmt.Errorf("invalid record id %q", record.ID)
+	}
+	paths := x.layout(record)
+	for _, p := range paths {
+		if !isLocalSlashPath(p) {
+			return nil, fmt.Errorf("%s: invalid record path %q", record.ID, p)
+		}
+	}
+	return paths, nil
+}
+
+// Write 按照布局写入一条记录，返回写入的文件的相对路径
+func (x *DirectoryWriter[EcosystemSpecific, DatabaseSpecific]) Write(record *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ([]string, error) {
+	paths, err := x.Paths(record)
+	if err != nil {
+		return nil, err
+	}
+	recordBytes, err := MarshalToFormattedJson(record)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", record.ID, err)
+	}
+	for _, p := range paths {
+		if err := writeFileAtomic(filepath.Join(x.root, filepath.FromSlash(p)), recordBytes); err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
+
+// WriteAll 写入多条记录，nil会被忽略，遇到错误的时候停止并返回这个错误
+func (x *DirectoryWriter[EcosystemSpecific, DatabaseSpecific]) WriteAll(records []*OsvSchema[EcosystemSpecific, DatabaseSpecific]) error {
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		if _, err := x.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteZip 把影响了ecosystem的记录打包写到relPath，比如 PyPI/all.zip ，压缩包中的文件名为 <ID>.json ，按照编号排列。
+// 和 EcosystemLayout 一样按照去掉 :<RELEASE> 后缀的ecosystem比较，比如 Debian 包含 Debian:11 的记录，ecosystem为空的时候打包所有的记录，nil会被忽略。
+// 记录可以通过 LoadDirectory 从写好的目录中重新加载
+func (x *DirectoryWriter[EcosystemSpecific, DatabaseSpecific]) WriteZip(relPath string, ecosystem Ecosystem, records []*OsvSchema[EcosystemSpecific, DatabaseSpecific]) error {
+	if !isLocalSlashPath(relPath) {
+		return fmt.Errorf("invalid zip path %q", relPath)
+	}
+	byID := make(map[string]*OsvSchema[EcosystemSpecific, DatabaseSpecific])
+	for _, record := range records {
+		if record != nil && (ecosystem == "" || affectsBaseEcosystem(record.Affected, ecosystem.Base())) {
+			byID[record.ID] = record
+		}
+	}
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	buffer := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(buffer)
+	for _, id := range ids {
+		record := byID[id]
+		recordBytes, err := MarshalToFormattedJson(record)
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		// 使用记录的修改时间作为文件的时间，保证相同的记录打出来的包是一样的
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: id + ".json", Method: zip.Deflate, Modified: record.Modified.UTC()})
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(recordBytes); err != nil {
+			return err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(x.root, filepath.FromSlash(relPath)), buffer.Bytes())
+}
+
+// WriteModifiedIndex 写入和osv.dev的 modified_id.csv 格式一样的索引到relPath，每行是 <modified>,<记录的路径> ，
+// 记录的路径是相对于索引所在目录、去掉 .json 扩展名的路径，只包含索引所在目录下的记录，按照修改时间从新到旧排列。
+// 比如使用 EcosystemLayout 时根目录下的索引为 2024-01-02T03:04:05Z,PyPI/PYSEC-2024-1 ， PyPI/modified_id.csv 中为 2024-01-02T03:04:05Z,PYSEC-2024-1
+func (x *DirectoryWriter[EcosystemSpecific, DatabaseSpecific]) WriteModifiedIndex(relPath string, records []*OsvSchema[EcosystemSpecific, DatabaseSpecific]) error {
+	if !isLocalSlashPath(relPath) {
+		return fmt.Errorf("invalid index path %q", relPath)
+	}
+	dir := path.Dir(relPath)
+
+	type indexEntry struct {
+		modified time.Time
+		name     string
+	}
+	entries := make([]indexEntry, 0)
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		paths, err := x.Paths(record)
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			name := p
+			if dir != "." {
+				if !strings.HasPrefix(p, dir+"/") {
+					continue
+				}
+				name = strings.TrimPrefix(p, dir+"/")
+			}
+			name = strings.TrimSuffix(name, ".json")
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			entries = append(entries, indexEntry{modified: record.Modified.UTC(), name: name})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].modified.Equal(entries[j].modified) {
+			return entries[i].modified.After(entries[j].modified)
+		}
+		return entries[i].name < entries[j].name
+	})
+
+	buffer := &bytes.Buffer{}
+	for _, entry := range entries {
+		buffer.WriteString(entry.modified.Format(time.RFC3339))
+		buffer.WriteByte(',')
+		buffer.WriteString(entry.name)
+		buffer.WriteByte('\n')
+	}
+	return writeFileAtomic(filepath.Join(x.root, filepath.FromSlash(relPath)), buffer.Bytes())
+}
+
+// 判断是否影响了给定的ecosystem的某个版本
+func affectsBaseEcosystem[EcosystemSpecific, DatabaseSpecific any](affectedSlice AffectedSlice[EcosystemSpecific, DatabaseSpecific], base Ecosystem) bool {
+	for _, affected := range affectedSlice {
+		if affected != nil && affected.Package != nil && affected.Package.Ecosystem.Base() == base {
+			return true
+		}
+	}
+	return false
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// MarshalToFormattedJson 序列化为缩进两个空格、以换行结尾的JSON，相同的记录总是输出相同的字节
+func MarshalToFormattedJson[EcosystemSpecific, DatabaseSpecific any](osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(osvSchema); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// 判断是否是不会跳出根目录的相对路径
+func isLocalSlashPath(p string) bool {
+	if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, `\`) {
+		return false
+	}
+	for _, segment := range strings.Split(p, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// 先写到同一目录下的临时文件再重命名，保证其它进程要么看到旧的文件，要么看到完整的新文件
+func writeFileAtomic(filePath string, data []byte) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tempPath := file.Name()
+	ok := false
+	defer func() {
+		if !ok {
+			_ = file.Close()
+			_ = os.Remove(tempPath)
+		}
+	}()
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Chmod(0644); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, filePath); err != nil {
+		return err
+	}
+	ok = true
+	return nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,125 @@
+package osv_schema
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWriterTestRecord(id string, modified time.Time, packages ...Package) *OsvSchema[any, any] {
+	r := &OsvSchema[any, any]{ID: id, Modified: modified, Summary: "summary"}
+	for i := range packages {
+		r.Affected = append(r.Affected, &Affected[any, any]{Package: &packages[i]})
+	}
+	return r
+}
+
+func TestDirectoryLayout(t *testing.T) {
+	r := newWriterTestRecord("GHSA-xxxx-yyyy-zzzz", time.Time{},
+		Package{Ecosystem: EcosystemNpm, Name: "@scope/name"},
+		Package{Ecosystem: EcosystemNpm, Name: "other"},
+		Package{Ecosystem: EcosystemPyPI, Name: "pkg"},
+	)
+	assert.Equal(t, []string{"npm/GHSA-xxxx-yyyy-zzzz.json", "PyPI/GHSA-xxxx-yyyy-zzzz.json"}, EcosystemLayout(r))
+	assert.Equal(t, []string{"npm/@scope/name/GHSA-xxxx-yyyy-zzzz.json", "npm/other/GHSA-xxxx-yyyy-zzzz.json", "PyPI/pkg/GHSA-xxxx-yyyy-zzzz.json"}, EcosystemPackageLayout(r))
+	assert.Equal(t, []string{"GHSA/GHSA-xxxx-yyyy-zzzz.json"}, IDPrefixLayout(r))
+	assert.Equal(t, []string{"foo.json"}, EcosystemLayout(newWriterTestRecord("foo", time.Time{})))
+
+	r.Affected = append(AffectedSlice[any, any]{nil}, r.Affected...)
+	assert.Equal(t, []string{"npm/GHSA-xxxx-yyyy-zzzz.json", "PyPI/GHSA-xxxx-yyyy-zzzz.json"}, EcosystemLayout(r))
+	assert.Len(t, EcosystemPackageLayout(r), 3)
+
+	// 带有 :<RELEASE> 后缀的ecosystem放在基础的ecosystem的目录下，包名中不能出现在文件名中的字符会被转义
+	r = newWriterTestRecord("DSA-1", time.Time{},
+		Package{Ecosystem: "Debian:11", Name: "openssl"},
+		Package{Ecosystem: "Debian:12", Name: "openssl"},
+		Package{Ecosystem: EcosystemMaven, Name: "org.apache:commons"},
+		Package{Ecosystem: EcosystemGit, Name: "https://github.com/a/b"},
+		Package{Ecosystem: EcosystemNpm, Name: ".."},
+	)
+	assert.Equal(t, []string{"Debian/DSA-1.json", "Maven/DSA-1.json", "GIT/DSA-1.json", "npm/DSA-1.json"}, EcosystemLayout(r))
+	assert.Equal(t, []string{
+		"Debian/openssl/DSA-1.json",
+		"Maven/org.apache%3Acommons/DSA-1.json",
+		"GIT/https%3A%2F%2Fgithub.com%2Fa%2Fb/DSA-1.json",
+		"npm/%2E%2E/DSA-1.json",
+	}, EcosystemPackageLayout(r))
+	paths, err := NewDirectoryWriter[any, any](t.TempDir(), &DirectoryWriterOptions[any, any]{Layout: EcosystemPackageLayout[any, any]}).Paths(r)
+	assert.Nil(t, err)
+	assert.Len(t, paths, 4)
+}
+
+func TestDirectoryWriter(t *testing.T) {
+	root := t.TempDir()
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	records := []*OsvSchema[any, any]{
+		newWriterTestRecord("PYSEC-2024-1", older, Package{Ecosystem: EcosystemPyPI, Name: "a"}),
+		newWriterTestRecord("GHSA-2", newer, Package{Ecosystem: EcosystemPyPI, Name: "b"}, Package{Ecosystem: EcosystemNpm, Name: "c"}),
+	}
+	writer := NewDirectoryWriter[any, any](root, nil)
+	assert.Nil(t, writer.WriteAll(records))
+
+	recordBytes, err := os.ReadFile(filepath.Join(root, "PyPI", "GHSA-2.json"))
+	assert.Nil(t, err)
+	expected, err := MarshalToFormattedJson(records[1])
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(recordBytes))
+	assert.Contains(t, string(recordBytes), "\n  \"id\": \"GHSA-2\",\n")
+	assert.FileExists(t, filepath.Join(root, "npm", "GHSA-2.json"))
+
+	// 重新加载写好的目录，生成索引和压缩包
+	loaded, err := LoadDirectory[any, any](context.Background(), root, nil)
+	assert.Nil(t, err)
+	assert.Len(t, loaded, 3)
+	assert.Nil(t, writer.WriteModifiedIndex("modified_id.csv", records))
+	assert.Nil(t, writer.WriteModifiedIndex("PyPI/modified_id.csv", records))
+	index, err := os.ReadFile(filepath.Join(root, "modified_id.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-02-01T00:00:00Z,PyPI/GHSA-2\n2024-02-01T00:00:00Z,npm/GHSA-2\n2024-01-01T00:00:00Z,PyPI/PYSEC-2024-1\n", string(index))
+	index, err = os.ReadFile(filepath.Join(root, "PyPI", "modified_id.csv"))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-02-01T00:00:00Z,GHSA-2\n2024-01-01T00:00:00Z,PYSEC-2024-1\n", string(index))
+
+	assert.Nil(t, writer.WriteZip("PyPI/all.zip", EcosystemPyPI, records))
+	zipBytes, err := os.ReadFile(filepath.Join(root, "PyPI", "all.zip"))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteZip("PyPI/all.zip", EcosystemPyPI, []*OsvSchema[any, any]{records[1], records[0]}))
+	zipBytesAgain, err := os.ReadFile(filepath.Join(root, "PyPI", "all.zip"))
+	assert.Nil(t, err)
+	assert.Equal(t, zipBytes, zipBytesAgain)
+
+	zipReader, err := OpenZipFile[any, any](filepath.Join(root, "PyPI", "all.zip"), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GHSA-2.json", "PYSEC-2024-1.json"}, zipReader.Paths())
+	assert.Nil(t, zipReader.Close())
+	assert.Nil(t, writer.WriteZip("npm/all.zip", EcosystemNpm, records))
+	npmZip, err := zip.OpenReader(filepath.Join(root, "npm", "all.zip"))
+	assert.Nil(t, err)
+	assert.Len(t, npmZip.File, 1)
+	assert.Nil(t, npmZip.Close())
+
+	// 不会留下临时文件
+	entries, err := os.ReadDir(filepath.Join(root, "PyPI"))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 4)
+
+	// Debian 的压缩包包含 Debian:11 的记录，nil会被忽略
+	debianRecords := []*OsvSchema[any, any]{nil, newWriterTestRecord("DSA-1", older, Package{Ecosystem: "Debian:11", Name: "openssl"})}
+	assert.Nil(t, writer.WriteAll(debianRecords))
+	assert.Nil(t, writer.WriteModifiedIndex("Debian/modified_id.csv", debianRecords))
+	assert.Nil(t, writer.WriteZip("Debian/all.zip", EcosystemDebian, debianRecords))
+	debianZip, err := zip.OpenReader(filepath.Join(root, "Debian", "all.zip"))
+	assert.Nil(t, err)
+	assert.Len(t, debianZip.File, 1)
+	assert.Nil(t, debianZip.Close())
+
+	_, err = writer.Write(newWriterTestRecord("../evil", older))
+	assert.NotNil(t, err)
+	assert.NotNil(t, writer.WriteZip("../all.zip", "", records))
+}
@@ -33,6 +33,9 @@ type Event struct {
 	LastAffected string `mapstructure:"last_affected" json:"last_affected" yaml:"last_affected" db:"last_affected" bson:"last_affected" gorm:"column:last_affected"`
 
 	Limit string `mapstructure:"limit" json:"limit" yaml:"limit" db:"limit" bson:"limit" gorm:"column:limit"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Event{}
@@ -72,4 +75,20 @@ func (x *Event) Scan(src any) error {
 	return json.Unmarshal(bytes, &x)
 }
 
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type eventJson Event
+
+func (x *Event) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*eventJson)(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Event) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(eventJson(x), x.UnknownFields)
+}
+
 // ------------------------------------------------- --------------------------------------------------------------------
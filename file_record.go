@@ -0,0 +1,34 @@
+package osv_schema
+
+import (
+	"fmt"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// FileRecord 从压缩包或者目录中读取的一条漏洞记录
+type FileRecord[EcosystemSpecific, DatabaseSpecific any] struct {
+
+	// 记录所在的文件的路径，压缩包中的是压缩包内的路径
+	Path string
+
+	Record *OsvSchema[EcosystemSpecific, DatabaseSpecific]
+}
+
+// FileError 读取某个文件时的错误
+type FileError struct {
+	Path string
+	Err  error
+}
+
+var _ error = &FileError{}
+
+func (x *FileError) Error() string {
+	return fmt.Sprintf("%s: %s", x.Path, x.Err.Error())
+}
+
+func (x *FileError) Unwrap() error {
+	return x.Err
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,240 @@
+package osv_schema
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// CommitRef 表示某个Git仓库中的一个提交，通常是从引用的链接中解析出来的
+type CommitRef struct {
+
+	// 仓库的地址，比如 https://github.com/tensorflow/tensorflow
+	Repo string
+
+	// 提交的哈希，可能是缩写的
+	Commit string
+}
+
+// IsFullHash 判断提交哈希是否是完整的，GIT类型的范围中只能使用完整的哈希
+func (x *CommitRef) IsFullHash() bool {
+	return (len(x.Commit) == 40 || len(x.Commit) == 64) && isCommitHash(x.Commit)
+}
+
+// ParseCommitURL 从代码托管平台的提交链接中解析出仓库地址和提交哈希，支持以下几种链接，无法识别的链接第二个返回值为false：
+// GitHub:    https://github.com/{owner}/{repo}/commit/{hash} 以及 https://github.com/{owner}/{repo}/pull/{id}/commits/{hash}
+// GitLab:    https://gitlab.com/{group}/{repo}/-/commit/{hash} ，私有部署的GitLab也可以识别
+// Bitbucket: https://bitbucket.org/{owner}/{repo}/commits/{hash}
+// Gitiles:   https://chromium.googlesource.com/{path}/+/{hash}
+// cgit:      https://git.kernel.org/{path}/commit/?id={hash}
+func ParseCommitURL(rawURL string) (*CommitRef, bool) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return nil, false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	repoPrefix := "https://" + host
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	// cgit: /{path}/commit/?id={hash}
+	if id := u.Query().Get("id"); id != "" && len(segments) >= 2 && segments[len(segments)-1] == "commit" && isCommitHash(id) {
+		return newCommitRef(repoPrefix, segments[:len(segments)-1], id), true
+	}
+
+	// Gitiles: /{path}/+/{hash} ，哈希后面可能会带 ^! 或者 ^!/
+	for i, segment := range segments {
+		if segment == "+" && i > 0 && i+1 < len(segments) {
+			hash := strings.TrimSuffix(segments[i+1], "^!")
+			if isCommitHash(hash) {
+				return newCommitRef(repoPrefix, segments[:i], hash), true
+			}
+		}
+	}
+
+	switch host {
+	case "github.com":
+		// /{owner}/{repo}/commit/{hash} 或者 /{owner}/{repo}/pull/{id}/commits/{hash}
+		if len(segments) >= 4 && (segments[2] == "commit" || segments[2] == "commits") {
+			if hash := trimCommitSuffix(segments[3]); isCommitHash(hash) {
+				return newCommitRef(repoPrefix, segments[:2], hash), true
+			}
+		}
+		if len(segments) >= 6 && segments[2] == "pull" && segments[4] == "commits" {
+			if hash := trimCommitSuffix(segments[5]); isCommitHash(hash) {
+				return newCommitRef(repoPrefix, segments[:2], hash), true
+			}
+		}
+		return nil, false
+	case "bitbucket.org":
+		// /{owner}/{repo}/commits/{hash}
+		if len(segments) >= 4 && (segments[2] == "commits" || segments[2] == "commit") && isCommitHash(segments[3]) {
+			return newCommitRef(repoPrefix, segments[:2], segments[3]), true
+		}
+		return nil, false
+	}
+
+	// GitLab: /{group}/{subgroup...}/{repo}/-/commit/{hash} ，老版本的GitLab没有中间的 -
+	for i := len(segments) - 2; i >= 1; i-- {
+		if segments[i] != "commit" {
+			continue
+		}
+		hash := trimCommitSuffix(segments[i+1])
+		if !isCommitHash(hash) {
+			continue
+		}
+		repoSegments := segments[:i]
+		if repoSegments[len(repoSegments)-1] == "-" {
+			repoSegments = repoSegments[:len(repoSegments)-1]
+		} else if !strings.HasPrefix(host, "gitlab.") {
+			continue
+		}
+		if len(repoSegments) >= 2 {
+			return newCommitRef(repoPrefix, repoSegments, hash), true
+		}
+	}
+	return nil, false
+}
+
+// 去掉提交链接末尾的 .patch 和 .diff
+func trimCommitSuffix(hash string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(hash, ".patch"), ".diff")
+}
+
+func newCommitRef(repoPrefix string, repoSegments []string, hash string) *CommitRef {
+	return &CommitRef{
+		Repo:   repoPrefix + "/" + strings.Join(repoSegments, "/"),
+		Commit: strings.ToLower(hash),
+	}
+}
+
+// 用于比较两个仓库地址是否是同一个仓库
+func repoKey(repo string) string {
+	return strings.TrimSuffix(NormalizeReferenceURL(repo), ".git")
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// FixCommits 从FIX类型的引用中解析出所有的修复提交，无法识别的链接会被忽略，重复的提交只返回一次
+func (x References) FixCommits() []*CommitRef {
+	commits := make([]*CommitRef, 0)
+	seen := make(map[string]struct{})
+	for _, reference := range x.FilterByType(ReferenceTypeFix) {
+		commit, ok := ParseCommitURL(reference.URL)
+		if !ok {
+			continue
+		}
+		key := repoKey(commit.Repo) + "@" + commit.Commit
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// GitRange 返回给定仓库的GIT类型的范围，没有的话返回nil
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) GitRange(repo string) *Range[DatabaseSpecific] {
+	key := repoKey(repo)
+	for _, r := range x.Ranges {
+		if r != nil && r.Type == RangeTypeGit && repoKey(r.Repo) == key {
+			return r
+		}
+	}
+	return nil
+}
+
+// AddGitFixCommits 把修复提交作为 fixed 事件添加到对应仓库的GIT类型的范围中，没有对应的范围的话会新建一个从 0 开始的范围，
+// 已经存在的修复提交和缩写的提交哈希会被忽略，返回添加的事件的数量
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) AddGitFixCommits(commits ...*CommitRef) int {
+	count := 0
+	for _, commit := range commits {
+		if commit == nil || !commit.IsFullHash() {
+			continue
+		}
+		r := x.GitRange(commit.Repo)
+		if r == nil {
+			r = &Range[DatabaseSpecific]{
+				Type:   RangeTypeGit,
+				Repo:   commit.Repo,
+				Events: Events{{Introduced: "0"}},
+			}
+			x.Ranges = append(x.Ranges, r)
+		}
+		exists := false
+		for _, event := range r.Events {
+			if event != nil && strings.EqualFold(event.Fixed, commit.Commit) {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			r.Events = append(r.Events, &Event{Fixed: commit.Commit})
+			count++
+		}
+	}
+	return count
+}
+
+// SynthesizeGitRanges 和osv.dev一样从FIX类型的引用中提取修复提交，补充到影响范围的GIT类型的范围中，返回添加的事件的数量。
+// matchFunc 用于决定一个修复提交应该添加到哪些影响范围中，为nil的时候使用默认的规则：
+// 1. 已经有这个仓库的GIT类型的范围的影响范围
+// 2. 如果没有的话，ecosystem为GIT并且包名是这个仓库的影响范围
+// 3. 如果还没有的话，并且记录只有一个影响范围，则使用这个影响范围
+// 如果记录没有任何影响范围，则会新建一个只有范围没有包的影响范围，没有添加任何GIT类型的范围的时候不会新建
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) SynthesizeGitRanges(matchFunc func(affected *Affected[EcosystemSpecific, DatabaseSpecific], commit *CommitRef) bool) int {
+	commits := x.References.FixCommits()
+	if len(commits) == 0 {
+		return 0
+	}
+	// 没有影响范围的时候先放到一个临时的空影响范围中，确实添加了GIT类型的范围才保留
+	placeholder := len(x.Affected) == 0
+	if placeholder {
+		x.Affected = AffectedSlice[EcosystemSpecific, DatabaseSpecific]{{}}
+	}
+
+	count := 0
+	for _, commit := range commits {
+		var matched AffectedSlice[EcosystemSpecific, DatabaseSpecific]
+		if matchFunc != nil {
+			matched = x.Affected.Filter(func(affected *Affected[EcosystemSpecific, DatabaseSpecific]) bool {
+				return matchFunc(affected, commit)
+			})
+		} else {
+			matched = x.defaultGitRangeTargets(commit)
+		}
+		for _, affected := range matched {
+			count += affected.AddGitFixCommits(commit)
+		}
+	}
+	if placeholder && len(x.Affected[0].Ranges) == 0 {
+		x.Affected = nil
+	}
+	return count
+}
+
+// 默认的修复提交应该添加到哪些影响范围中的规则，@see SynthesizeGitRanges
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) defaultGitRangeTargets(commit *CommitRef) AffectedSlice[EcosystemSpecific, DatabaseSpecific] {
+	matched := x.Affected.Filter(func(affected *Affected[EcosystemSpecific, DatabaseSpecific]) bool {
+		return affected != nil && affected.GitRange(commit.Repo) != nil
+	})
+	if len(matched) != 0 {
+		return matched
+	}
+	matched = x.Affected.Filter(func(affected *Affected[EcosystemSpecific, DatabaseSpecific]) bool {
+		return affected != nil && affected.Package != nil && affected.Package.Ecosystem == EcosystemGit &&
+			repoKey(affected.Package.Name) == repoKey(commit.Repo)
+	})
+	if len(matched) != 0 {
+		return matched
+	}
+	if len(x.Affected) == 1 && x.Affected[0] != nil {
+		return x.Affected
+	}
+	return nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,53 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseCommitURL(t *testing.T) {
+	hash := "8741e57d163a079db05a7107a7609af70931def4"
+	for rawURL, repo := range map[string]string{
+		"https://github.com/tensorflow/tensorflow/commit/" + hash:                               "https://github.com/tensorflow/tensorflow",
+		"https://github.com/tensorflow/tensorflow/pull/1/commits/" + hash:                       "https://github.com/tensorflow/tensorflow",
+		"https://gitlab.com/gitlab-org/gitlab/-/commit/" + hash:                                 "https://gitlab.com/gitlab-org/gitlab",
+		"https://gitlab.gnome.org/GNOME/glib/commit/" + hash:                                    "https://gitlab.gnome.org/GNOME/glib",
+		"https://bitbucket.org/foo/bar/commits/" + hash:                                         "https://bitbucket.org/foo/bar",
+		"https://chromium.googlesource.com/v8/v8/+/" + hash + "^!":                              "https://chromium.googlesource.com/v8/v8",
+		"https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git/commit/?id=" + hash: "https://git.kernel.org/pub/scm/linux/kernel/git/torvalds/linux.git",
+	} {
+		commit, ok := ParseCommitURL(rawURL)
+		assert.True(t, ok, rawURL)
+		assert.Equal(t, &CommitRef{Repo: repo, Commit: hash}, commit, rawURL)
+	}
+
+	_, ok := ParseCommitURL("https://github.com/tensorflow/tensorflow/releases/tag/v2.10.0")
+	assert.False(t, ok)
+}
+
+func TestOsvSchema_SynthesizeGitRanges(t *testing.T) {
+	r, err := UnmarshalFromJsonFile[any, any]("test_data/GHSA-vxv8-r8q2-63xw.json")
+	assert.Nil(t, err)
+	r.References[2].Type = ReferenceTypeFix
+
+	// 多个影响范围的时候默认规则无法决定添加到哪里
+	assert.Equal(t, 0, r.SynthesizeGitRanges(nil))
+
+	count := r.SynthesizeGitRanges(func(affected *Affected[any, any], commit *CommitRef) bool {
+		return affected.Package.Name == "tensorflow"
+	})
+	assert.Equal(t, 3, count)
+	gitRange := r.Affected[0].GitRange("https://github.com/tensorflow/tensorflow.git")
+	assert.Equal(t, Events{{Introduced: "0"}, {Fixed: "8741e57d163a079db05a7107a7609af70931def4"}}, gitRange.Events)
+
+	// 重复添加不会产生新的事件
+	assert.Equal(t, 0, r.SynthesizeGitRanges(nil))
+
+	// 没有影响范围、修复提交又都是缩写的哈希时不会新建空的影响范围
+	empty := &OsvSchema[any, any]{ID: "OSV-1", References: References{{Type: ReferenceTypeFix, URL: "https://github.com/foo/bar/commit/abc1234"}}}
+	assert.Equal(t, 0, empty.SynthesizeGitRanges(nil))
+	assert.Empty(t, empty.Affected)
+	empty.References = append(empty.References, &Reference{Type: ReferenceTypeFix, URL: "https://github.com/foo/bar/commit/8741e57d163a079db05a7107a7609af70931def4"})
+	assert.Equal(t, 1, empty.SynthesizeGitRanges(nil))
+	assert.Len(t, empty.Affected, 1)
+}
@@ -0,0 +1,141 @@
+package osv_schema
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// GitTag Git仓库中的一个标签
+type GitTag struct {
+
+	// 标签名，不包含 refs/tags/ 前缀
+	Name string
+
+	// 标签指向的提交，附注标签会被解引用为它指向的提交
+	Commit string
+}
+
+// GitRepository 能够查询祖先关系并列出所有标签的Git仓库
+type GitRepository interface {
+	CommitGraph
+
+	// Tags 列出仓库中的所有标签
+	Tags() ([]*GitTag, error)
+}
+
+var _ GitRepository = &LocalGitCommitGraph{}
+
+// Tags 列出仓库中所有指向提交的标签，指向树或者文件的标签会被忽略
+func (x *LocalGitCommitGraph) Tags() ([]*GitTag, error) {
+	output, err := runGit(x.Dir, "for-each-ref", "refs/tags", "--format=%(refname:short) %(objectname) %(*objectname)")
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]*GitTag, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		// 附注标签的第三列是解引用之后的对象
+		commit := fields[len(fields)-1]
+		if !x.HasCommit(commit) {
+			continue
+		}
+		tags = append(tags, &GitTag{Name: fields[0], Commit: commit})
+	}
+	return tags, scanner.Err()
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// GitVersionsOptions 从GIT类型的范围中枚举受影响的版本时的选项
+type GitVersionsOptions struct {
+
+	// 把标签名转换为版本号，第二个返回值为false表示忽略这个标签，为nil的时候原样使用标签名
+	NormalizeTag func(tag string) (string, bool)
+}
+
+// TrimTagPrefix 返回一个去掉标签名的前缀的转换函数，比如 TrimTagPrefix("v", "release-") 会把 v1.2.3 和 release-1.2.3 都转换为 1.2.3 ，
+// 没有任何一个前缀的标签原样保留
+func TrimTagPrefix(prefixes ...string) func(tag string) (string, bool) {
+	return func(tag string) (string, bool) {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(tag, prefix) && len(tag) > len(prefix) {
+				return tag[len(prefix):], true
+			}
+		}
+		return tag, true
+	}
+}
+
+// AffectedTags 返回所有被这个GIT类型的范围影响到的标签，按标签名排序
+func (x *Range[DatabaseSpecific]) AffectedTags(repository GitRepository) ([]*GitTag, error) {
+	tags, err := repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	affectedTags := make([]*GitTag, 0)
+	for _, tag := range tags {
+		affected, err := x.IsCommitAffected(repository, tag.Commit)
+		if err != nil {
+			return nil, err
+		}
+		if affected {
+			affectedTags = append(affectedTags, tag)
+		}
+	}
+	sort.Slice(affectedTags, func(i, j int) bool {
+		return affectedTags[i].Name < affectedTags[j].Name
+	})
+	return affectedTags, nil
+}
+
+// EnumerateGitVersions 和osv.dev一样把给定仓库的GIT类型的范围展开为受影响的标签，标签转换为版本号之后合并到 Versions 中，
+// repository 需要是 repo 的本地克隆，已经存在的版本会被保留，返回新增的版本
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) EnumerateGitVersions(repo string, repository GitRepository, options *GitVersionsOptions) ([]string, error) {
+	normalizeTag := func(tag string) (string, bool) {
+		return tag, true
+	}
+	if options != nil && options.NormalizeTag != nil {
+		normalizeTag = options.NormalizeTag
+	}
+
+	existing := make(map[string]struct{}, len(x.Versions))
+	for _, version := range x.Versions {
+		existing[version] = struct{}{}
+	}
+
+	key := repoKey(repo)
+	added := make([]string, 0)
+	for _, r := range x.Ranges {
+		if r == nil || r.Type != RangeTypeGit || repoKey(r.Repo) != key {
+			continue
+		}
+		tags, err := r.AffectedTags(repository)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			version, ok := normalizeTag(tag.Name)
+			if !ok {
+				continue
+			}
+			if _, exists := existing[version]; exists {
+				continue
+			}
+			existing[version] = struct{}{}
+			added = append(added, version)
+		}
+	}
+	sort.Strings(added)
+	x.Versions = append(x.Versions, added...)
+	return added, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -5,11 +5,11 @@ go 1.18
 require (
 	github.com/golang-infrastructure/go-pointer v0.0.2
 	github.com/stretchr/testify v1.8.3
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/golang-infrastructure/go-reflect-utils v0.0.0-20221130143747-965ef2eb09c3 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
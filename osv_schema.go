@@ -1,6 +1,7 @@
 package osv_schema
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -8,7 +9,7 @@ import (
 // 参考文档： https://ossf.github.io/osv-schema/
 type OsvSchema[EcosystemSpecific, DatabaseSpecific any] struct {
 
-	// OSV的版本
+	// OSV的版本，比如 1.7.0 ，@see CurrentSchemaVersion
 	SchemaVersion string `mapstructure:"schema_version" json:"schema_version" yaml:"schema_version" db:"schema_version" bson:"schema_version" gorm:"column:schema_version"`
 	ID            string `mapstructure:"id" json:"id" yaml:"id" db:"id" bson:"id" gorm:"column:id"`
 
@@ -18,14 +19,18 @@ type OsvSchema[EcosystemSpecific, DatabaseSpecific any] struct {
 	// 发布日期
 	Published time.Time `mapstructure:"published" json:"published" yaml:"published" db:"published" bson:"published" gorm:"column:published"`
 
-	// TODO 2023-5-23 19:10:45 草这个字段啥意思...
-	Withdrawn string `mapstructure:"withdrawn" json:"withdrawn" yaml:"withdrawn" db:"withdrawn" bson:"withdrawn" gorm:"column:withdrawn"`
+	// 撤回日期，RFC3339格式的时间戳，有值的话表示这个漏洞已经被撤回了，不应该再被使用，没有撤回的漏洞此字段为空
+	Withdrawn *time.Time `mapstructure:"withdrawn" json:"withdrawn,omitempty" yaml:"withdrawn,omitempty" db:"withdrawn" bson:"withdrawn,omitempty" gorm:"column:withdrawn"`
 
 	// 漏洞的编号
 	Aliases Aliases `mapstructure:"aliases" json:"aliases" yaml:"aliases" db:"aliases" bson:"aliases" gorm:"column:aliases;serializer:json"`
 
+	// 相关但不是同一个的漏洞的编号
 	Related Related `mapstructure:"related" json:"related" yaml:"related" db:"related" bson:"related" gorm:"column:related;serializer:json"`
 
+	// 当前漏洞派生自哪些上游漏洞，Schema 1.7.0 中新增
+	Upstream Upstream `mapstructure:"upstream" json:"upstream,omitempty" yaml:"upstream,omitempty" db:"upstream" bson:"upstream,omitempty" gorm:"column:upstream;serializer:json"`
+
 	// 可以认为是漏洞标题啥的
 	Summary string `mapstructure:"summary" json:"summary" yaml:"summary" db:"summary" bson:"summary" gorm:"column:summary"`
 
@@ -44,7 +49,91 @@ type OsvSchema[EcosystemSpecific, DatabaseSpecific any] struct {
 	// 漏洞库自己的实现规范
 	DatabaseSpecific DatabaseSpecific `mapstructure:"database_specific" json:"database_specific" yaml:"database_specific" db:"database_specific" bson:"database_specific" gorm:"column:database_specific;serializer:json"`
 
-	Credits *Credits `mapstructure:"credits" json:"credits" yaml:"credits" db:"credits" bson:"credits" gorm:"column:credits;serializer:json"`
+	// 漏洞的贡献者
+	Credits CreditsSlice `mapstructure:"credits" json:"credits" yaml:"credits" db:"credits" bson:"credits" gorm:"column:credits;serializer:json"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
+}
+
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type osvSchemaJson[EcosystemSpecific, DatabaseSpecific any] OsvSchema[EcosystemSpecific, DatabaseSpecific]
+
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*osvSchemaJson[EcosystemSpecific, DatabaseSpecific])(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x OsvSchema[EcosystemSpecific, DatabaseSpecific]) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(osvSchemaJson[EcosystemSpecific, DatabaseSpecific](x), x.UnknownFields)
+}
+
+// IsWithdrawn 判断漏洞是否已经被撤回
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) IsWithdrawn() bool {
+	return x != nil && x.Withdrawn != nil && !x.Withdrawn.IsZero()
+}
+
+// WithdrawnAt 返回漏洞被撤回的时间，如果漏洞没有被撤回的话第二个返回值为false
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) WithdrawnAt() (time.Time, bool) {
+	if !x.IsWithdrawn() {
+		return time.Time{}, false
+	}
+	return *x.Withdrawn, true
+}
+
+// UnknownFieldPaths 返回反序列化时遇到的所有未知字段的路径，包括嵌套的结构体中的未知字段，比如 affected[0].ranges[0].events[1].foo
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) UnknownFieldPaths() []string {
+	if x == nil {
+		return nil
+	}
+	paths := appendUnknownFieldPaths(nil, "", x.UnknownFields)
+	for i, affected := range x.Affected {
+		if affected == nil {
+			continue
+		}
+		affectedPrefix := fmt.Sprintf("affected[%d]", i)
+		paths = appendUnknownFieldPaths(paths, affectedPrefix, affected.UnknownFields)
+		if affected.Package != nil {
+			paths = appendUnknownFieldPaths(paths, affectedPrefix+".package", affected.Package.UnknownFields)
+		}
+		for j, r := range affected.Ranges {
+			if r == nil {
+				continue
+			}
+			rangePrefix := fmt.Sprintf("%s.ranges[%d]", affectedPrefix, j)
+			paths = appendUnknownFieldPaths(paths, rangePrefix, r.UnknownFields)
+			for k, event := range r.Events {
+				if event != nil {
+					paths = appendUnknownFieldPaths(paths, fmt.Sprintf("%s.events[%d]", rangePrefix, k), event.UnknownFields)
+				}
+			}
+		}
+		for j, severity := range affected.Severity {
+			if severity != nil {
+				paths = appendUnknownFieldPaths(paths, fmt.Sprintf("%s.severity[%d]", affectedPrefix, j), severity.UnknownFields)
+			}
+		}
+	}
+	for i, severity := range x.Severity {
+		if severity != nil {
+			paths = appendUnknownFieldPaths(paths, fmt.Sprintf("severity[%d]", i), severity.UnknownFields)
+		}
+	}
+	for i, reference := range x.References {
+		if reference != nil {
+			paths = appendUnknownFieldPaths(paths, fmt.Sprintf("references[%d]", i), reference.UnknownFields)
+		}
+	}
+	for i, credits := range x.Credits {
+		if credits != nil {
+			paths = appendUnknownFieldPaths(paths, fmt.Sprintf("credits[%d]", i), credits.UnknownFields)
+		}
+	}
+	return paths
 }
 
 //var _ sql.Scanner = &OsvSchema[any, any]{}
@@ -0,0 +1,93 @@
+package osv_schema
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// QueryOptions 集合过滤、匹配等查询类API的通用选项，传nil的话使用默认选项
+type QueryOptions struct {
+
+	// 是否包含已经撤回的漏洞，默认情况下已撤回的漏洞会被排除掉
+	IncludeWithdrawn bool
+}
+
+// 判断某条漏洞记录在此选项下是否应该参与查询
+func (x *QueryOptions) accept(withdrawn bool) bool {
+	if !withdrawn {
+		return true
+	}
+	return x != nil && x.IncludeWithdrawn
+}
+
+// 从可变参数中取出查询选项
+func getQueryOptions(options []*QueryOptions) *QueryOptions {
+	if len(options) == 0 {
+		return nil
+	}
+	return options[0]
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// OsvSchemaSlice 表示多条漏洞记录的集合
+type OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific any] []*OsvSchema[EcosystemSpecific, DatabaseSpecific]
+
+// Filter 过滤漏洞记录，已撤回的漏洞默认会被排除掉，如果需要包含的话传入 QueryOptions.IncludeWithdrawn
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) Filter(filterFunc func(osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) bool, options ...*QueryOptions) OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific] {
+	queryOptions := getQueryOptions(options)
+	slice := make([]*OsvSchema[EcosystemSpecific, DatabaseSpecific], 0)
+	for _, item := range x {
+		if item == nil || !queryOptions.accept(item.IsWithdrawn()) {
+			continue
+		}
+		if filterFunc == nil || filterFunc(item) {
+			slice = append(slice, item)
+		}
+	}
+	return slice
+}
+
+// Active 返回所有没有被撤回的漏洞
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) Active() OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific] {
+	return x.Filter(nil)
+}
+
+// Withdrawn 返回所有已经被撤回的漏洞
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) Withdrawn() OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific] {
+	return x.Filter(func(osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) bool {
+		return osvSchema.IsWithdrawn()
+	}, &QueryOptions{IncludeWithdrawn: true})
+}
+
+// FindByID 根据漏洞编号查找漏洞，会同时匹配别名，找不到的话返回nil
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) FindByID(id string, options ...*QueryOptions) *OsvSchema[EcosystemSpecific, DatabaseSpecific] {
+	slice := x.Filter(func(osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) bool {
+		if osvSchema.ID == id {
+			return true
+		}
+		for _, alias := range osvSchema.Aliases {
+			if alias == id {
+				return true
+			}
+		}
+		return false
+	}, options...)
+	if len(slice) == 0 {
+		return nil
+	}
+	return slice[0]
+}
+
+// FilterByEcosystem 过滤出影响到给定包管理器的漏洞
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) FilterByEcosystem(ecosystem Ecosystem, options ...*QueryOptions) OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific] {
+	return x.Filter(func(osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) bool {
+		return osvSchema.Affected.HasEcosystem(ecosystem)
+	}, options...)
+}
+
+// FilterByPackage 过滤出影响到给定包的漏洞
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) FilterByPackage(ecosystem Ecosystem, name string, options ...*QueryOptions) OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific] {
+	return x.Filter(func(osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) bool {
+		return osvSchema.Affected.HasPackage(ecosystem, name)
+	}, options...)
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
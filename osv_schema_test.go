@@ -0,0 +1,25 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOsvSchema_IsWithdrawn(t *testing.T) {
+	active, err := UnmarshalFromJson[any, any]([]byte(`{"id": "GHSA-1", "modified": "2023-01-01T00:00:00Z"}`))
+	assert.Nil(t, err)
+	assert.False(t, active.IsWithdrawn())
+
+	withdrawn, err := UnmarshalFromJson[any, any]([]byte(`{"id": "GHSA-2", "modified": "2023-01-01T00:00:00Z", "withdrawn": "2023-02-01T10:00:00Z"}`))
+	assert.Nil(t, err)
+	assert.True(t, withdrawn.IsWithdrawn())
+	at, ok := withdrawn.WithdrawnAt()
+	assert.True(t, ok)
+	assert.Equal(t, "2023-02-01T10:00:00Z", at.Format("2006-01-02T15:04:05Z07:00"))
+
+	slice := OsvSchemaSlice[any, any]{active, withdrawn}
+	assert.Len(t, slice.Active(), 1)
+	assert.Len(t, slice.Withdrawn(), 1)
+	assert.Nil(t, slice.FindByID("GHSA-2"))
+	assert.NotNil(t, slice.FindByID("GHSA-2", &QueryOptions{IncludeWithdrawn: true}))
+}
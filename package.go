@@ -77,14 +77,114 @@ const (
 	// EcosystemRocky Rocky Linux	The Rocky Linux package ecosystem; the name is the name of the source package.
 	// The ecosystem string might optionally have a :<RELEASE> suffix to scope the package to a particular Rocky Linux
 	// release. <RELEASE> is a numeric version.
-	EcosystemRocky Ecosystem = "Rocky"
+	// 注意：osv-schema规范中的名字是 "Rocky Linux" ，之前的版本这个常量的值是 "Rocky" ，
+	// 用旧的值保存下来的数据可以通过 Ecosystem.Canonical 转换为新的值，@see EcosystemRockyLegacy
+	EcosystemRocky Ecosystem = "Rocky Linux"
+
+	// EcosystemRockyLegacy 之前的版本中 EcosystemRocky 的值，不是规范中的名字，只用来兼容已经保存下来的数据
+	//
+	// Deprecated: 使用 EcosystemRocky
+	EcosystemRockyLegacy Ecosystem = "Rocky"
 
 	// EcosystemAlmaLinux AlmaLinux package ecosystem; the name is the name of the source package. The ecosystem string
 	// might optionally have a :<RELEASE> suffix to scope the package to a particular AlmaLinux release. <RELEASE> is a
 	// numeric version.
 	EcosystemAlmaLinux Ecosystem = "AlmaLinux"
+
+	// EcosystemBitnami Bitnami	Bitnami's catalog of packaged applications; the name field is the Bitnami package name.
+	EcosystemBitnami Ecosystem = "Bitnami"
+
+	// EcosystemBioconductor Bioconductor	The Bioconductor ecosystem for R; the name field is a Bioconductor package name.
+	EcosystemBioconductor Ecosystem = "Bioconductor"
+
+	// EcosystemChainguard Chainguard	The Chainguard package ecosystem; the name is the name of the source package.
+	EcosystemChainguard Ecosystem = "Chainguard"
+
+	// EcosystemCRAN CRAN	The R package ecosystem; the name field is an R package name.
+	EcosystemCRAN Ecosystem = "CRAN"
+
+	// EcosystemGHC GHC	The Glasgow Haskell Compiler; the name field is the component name, e.g. GHC.
+	EcosystemGHC Ecosystem = "GHC"
+
+	// EcosystemGit GIT	For software that is not distributed through a package manager, e.g. C/C++ libraries; the name field
+	// is the URL of the Git repository, and the affected versions are the repository's tags.
+	EcosystemGit Ecosystem = "GIT"
+
+	// EcosystemHackage Hackage	The Haskell package ecosystem; the name field is a Haskell package name as published on Hackage.
+	EcosystemHackage Ecosystem = "Hackage"
+
+	// EcosystemMageia Mageia	The Mageia package ecosystem; the name is the name of the source package. The ecosystem
+	// string must have a :<RELEASE-NUMBER> suffix to scope the package to a particular Mageia release, e.g. Mageia:9.
+	EcosystemMageia Ecosystem = "Mageia"
+
+	// EcosystemMinimOS MinimOS	The MinimOS package ecosystem; the name is the name of the source package.
+	EcosystemMinimOS Ecosystem = "MinimOS"
+
+	// EcosystemOpenEuler openEuler	The openEuler package ecosystem; the name is the name of the source package. The
+	// ecosystem string might optionally have a :<RELEASE> suffix, e.g. openEuler:22.03-LTS.
+	EcosystemOpenEuler Ecosystem = "openEuler"
+
+	// EcosystemOpenSUSE openSUSE	The openSUSE package ecosystem; the name is the name of the package. The ecosystem
+	// string might optionally have a :<RELEASE> suffix, e.g. openSUSE:Leap 15.5.
+	EcosystemOpenSUSE Ecosystem = "openSUSE"
+
+	// EcosystemPhotonOS Photon OS	The Photon OS package ecosystem; the name is the name of the RPM package. The
+	// ecosystem string might optionally have a :<RELEASE> suffix, e.g. Photon OS:3.0.
+	EcosystemPhotonOS Ecosystem = "Photon OS"
+
+	// EcosystemRedHat Red Hat	The Red Hat package ecosystem; the name is the name of the binary package. The ecosystem
+	// string might optionally have a :<CPE> suffix to scope the package to a particular product.
+	EcosystemRedHat Ecosystem = "Red Hat"
+
+	// EcosystemSUSE SUSE	The SUSE package ecosystem; the name is the name of the package. The ecosystem string might
+	// optionally have a :<RELEASE> suffix, e.g. SUSE:Linux Enterprise Server 15 SP5.
+	EcosystemSUSE Ecosystem = "SUSE"
+
+	// EcosystemSwiftURL SwiftURL	The Swift package ecosystem; the name is a Git URL to the source of the package.
+	EcosystemSwiftURL Ecosystem = "SwiftURL"
+
+	// EcosystemUbuntu Ubuntu	The Ubuntu package ecosystem; the name field is the name of the source package. The
+	// ecosystem string has a :<RELEASE> suffix, optionally followed by :LTS or :Pro, e.g. Ubuntu:22.04:LTS.
+	EcosystemUbuntu Ecosystem = "Ubuntu"
+
+	// EcosystemWolfi Wolfi	The Wolfi package ecosystem; the name is the name of the source package.
+	EcosystemWolfi Ecosystem = "Wolfi"
 )
 
+// Base 去掉ecosystem中的 :<RELEASE> 这种后缀，返回包管理器本身，比如 Debian:11 返回 Debian
+func (x Ecosystem) Base() Ecosystem {
+	if index := strings.Index(string(x), ":"); index >= 0 {
+		return x[:index]
+	}
+	return x
+}
+
+// 旧的、不规范的ecosystem名字到规范中的名字的映射
+var legacyEcosystems = map[Ecosystem]Ecosystem{
+	EcosystemRockyLegacy: EcosystemRocky,
+}
+
+// Canonical 把旧的、不规范的ecosystem名字转换为规范中的名字， :<RELEASE> 后缀会被保留，比如 Rocky:9 返回 Rocky Linux:9 ，
+// 已经是规范的名字的话原样返回
+func (x Ecosystem) Canonical() Ecosystem {
+	canonical, ok := legacyEcosystems[x.Base()]
+	if !ok {
+		return x
+	}
+	if index := strings.Index(string(x), ":"); index >= 0 {
+		return canonical + x[index:]
+	}
+	return canonical
+}
+
+// Release 返回ecosystem中 :<RELEASE> 这种后缀中的版本部分，没有的话返回空字符串，比如 Debian:11 返回 11
+func (x Ecosystem) Release() string {
+	if index := strings.Index(string(x), ":"); index >= 0 {
+		return string(x[index+1:])
+	}
+	return ""
+}
+
 // ------------------------------------------------- --------------------------------------------------------------------
 
 //	"package": {
@@ -101,6 +201,9 @@ type Package struct {
 
 	// https://github.com/package-url/purl-spec
 	PackageUrl string `mapstructure:"purl" json:"purl" yaml:"purl" db:"purl" bson:"purl" gorm:"column:purl"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Package{}
@@ -124,6 +227,22 @@ func (x *Package) Scan(src any) error {
 	return json.Unmarshal(bytes, &x)
 }
 
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type packageJson Package
+
+func (x *Package) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*packageJson)(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Package) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(packageJson(x), x.UnknownFields)
+}
+
 // IsMaven 判断包的类型是否是Maven的包
 func (x *Package) IsMaven() bool {
 	return x.Ecosystem == EcosystemMaven
@@ -0,0 +1,44 @@
+package osv_schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcosystem_Base(t *testing.T) {
+	assert.Equal(t, EcosystemDebian, Ecosystem("Debian:11").Base())
+	assert.Equal(t, "11", Ecosystem("Debian:11").Release())
+	assert.Equal(t, EcosystemUbuntu, Ecosystem("Ubuntu:22.04:LTS").Base())
+	assert.Equal(t, "22.04:LTS", Ecosystem("Ubuntu:22.04:LTS").Release())
+	assert.Equal(t, EcosystemPhotonOS, Ecosystem("Photon OS:3.0").Base())
+	assert.Equal(t, EcosystemNpm, EcosystemNpm.Base())
+	assert.Equal(t, "", EcosystemNpm.Release())
+}
+
+func TestEcosystem_Canonical(t *testing.T) {
+	assert.Equal(t, EcosystemRocky, EcosystemRockyLegacy.Canonical())
+	assert.Equal(t, Ecosystem("Rocky Linux:9"), Ecosystem("Rocky:9").Canonical())
+	assert.Equal(t, Ecosystem("Rocky Linux:9"), Ecosystem("Rocky Linux:9").Canonical())
+	assert.Equal(t, EcosystemPyPI, EcosystemPyPI.Canonical())
+}
+
+func TestEcosystem_Newer(t *testing.T) {
+	r, err := UnmarshalFromJson[any, any]([]byte(`{"id": "BIT-1", "affected": [
+		{"package": {"ecosystem": "Bitnami", "name": "wordpress"}},
+		{"package": {"ecosystem": "Red Hat:enterprise_linux:8", "name": "kernel"}},
+		{"package": {"ecosystem": "openSUSE:Leap 15.5", "name": "curl"}}
+	]}`))
+	assert.Nil(t, err)
+	assert.True(t, r.Affected.HasEcosystem(EcosystemBitnami))
+	assert.Equal(t, EcosystemRedHat, r.Affected[1].Package.Ecosystem.Base())
+	assert.Equal(t, "enterprise_linux:8", r.Affected[1].Package.Ecosystem.Release())
+	assert.Equal(t, EcosystemOpenSUSE, r.Affected[2].Package.Ecosystem.Base())
+
+	_, err = GetVersionComparator(EcosystemBitnami)
+	assert.Nil(t, err)
+	marshal, err := json.Marshal(r.Affected[0].Package)
+	assert.Nil(t, err)
+	assert.Contains(t, string(marshal), `"ecosystem":"Bitnami"`)
+}
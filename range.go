@@ -59,6 +59,9 @@ type Range[DatabaseSpecific any] struct {
 
 	// 由具体实现的数据库决定
 	DatabaseSpecific DatabaseSpecific `mapstructure:"database_specific" json:"database_specific" yaml:"database_specific" db:"database_specific" bson:"database_specific" gorm:"column:database_specific;serializer:json"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Range[any]{}
@@ -82,4 +85,20 @@ func (x *Range[DatabaseSpecific]) Scan(src any) error {
 	return json.Unmarshal(bytes, &x)
 }
 
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type rangeJson[DatabaseSpecific any] Range[DatabaseSpecific]
+
+func (x *Range[DatabaseSpecific]) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*rangeJson[DatabaseSpecific])(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Range[DatabaseSpecific]) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(rangeJson[DatabaseSpecific](x), x.UnknownFields)
+}
+
 // ------------------------------------------------- --------------------------------------------------------------------
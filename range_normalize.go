@@ -0,0 +1,271 @@
+package osv_schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// RangeConflictError 影响范围中的事件互相矛盾，比如同一个版本在一个范围中是 fixed ，在另一个范围中是 last_affected
+type RangeConflictError struct {
+
+	// 包名，没有包的时候为空
+	Package string
+
+	// 每一处矛盾的描述
+	Conflicts []string
+}
+
+var _ error = &RangeConflictError{}
+
+func (x *RangeConflictError) Error() string {
+	if x.Package == "" {
+		return "contradictory ranges: " + strings.Join(x.Conflicts, "; ")
+	}
+	return fmt.Sprintf("contradictory ranges of %s: %s", x.Package, strings.Join(x.Conflicts, "; "))
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// Normalize 规范化影响范围，返回一个新的 Affected ，原来的不会被修改：
+//   - SEMVER和ECOSYSTEM类型的范围中除了事件之外的字段（repo、database_specific以及不认识的字段）都相同的会合并为一个范围，
+//     重叠或者首尾相接的区间合并为最少的不重叠的区间，事件按照版本从小到大排列， limit 事件会被折算到区间中
+//   - GIT类型的范围无法比较提交的先后，只去掉重复的事件和重复的范围
+//   - Versions 去重，能比较版本的时候按照从小到大排列
+//
+// 范围之间互相矛盾的时候不会自作主张地选择其中一个，而是返回 *RangeConflictError
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) Normalize() (*Affected[EcosystemSpecific, DatabaseSpecific], error) {
+	normalized := *x
+	normalized.Ranges = make([]*Range[DatabaseSpecific], 0, len(x.Ranges))
+
+	conflicts := make([]string, 0)
+	// 合并之后的范围的key，以及每个合并之后的范围的区间
+	mergedKeys := make(map[string]*Range[DatabaseSpecific])
+	mergedIntervals := make(map[*Range[DatabaseSpecific]]VersionIntervals)
+	fixedVersions := make(map[RangeType][]string)
+	lastAffectedVersions := make(map[RangeType][]string)
+	for _, r := range x.Ranges {
+		if r == nil {
+			continue
+		}
+		if r.Type == RangeTypeGit {
+			if git := normalizeGitRange(r); !containsEqualGitRange(normalized.Ranges, git) {
+				normalized.Ranges = append(normalized.Ranges, git)
+			}
+			continue
+		}
+
+		comparator, err := x.versionComparator(r.Type)
+		if err != nil {
+			return nil, err
+		}
+		// 多个数据源合并的时候经常会有完全重复的事件，重复的事件不算矛盾
+		intervals, rangeConflicts, err := eventsToIntervals(comparator, dedupeEvents(r.Events))
+		if err != nil {
+			return nil, err
+		}
+		conflicts = append(conflicts, rangeConflicts...)
+
+		key, err := rangeMergeKey(r)
+		if err != nil {
+			return nil, err
+		}
+		merged, ok := mergedKeys[key]
+		if !ok {
+			copied := *r
+			merged = &copied
+			mergedKeys[key] = merged
+			normalized.Ranges = append(normalized.Ranges, merged)
+		}
+		mergedIntervals[merged] = append(mergedIntervals[merged], intervals...)
+		for _, event := range r.Events {
+			switch {
+			case event == nil:
+			case event.IsFixed():
+				fixedVersions[r.Type] = append(fixedVersions[r.Type], event.Fixed)
+			case event.IsLastAffected():
+				lastAffectedVersions[r.Type] = append(lastAffectedVersions[r.Type], event.LastAffected)
+			}
+		}
+	}
+
+	// 同一个版本不能既是修复的版本又是最后受影响的版本，版本按照比较器判断是否相等，比如PEP 440中的 1.0 和 1.0.0
+	for _, rangeType := range []RangeType{RangeTypeSemver, RangeTypeEcosystem} {
+		if len(fixedVersions[rangeType]) == 0 || len(lastAffectedVersions[rangeType]) == 0 {
+			continue
+		}
+		comparator, err := x.versionComparator(rangeType)
+		if err != nil {
+			return nil, err
+		}
+		reported := make([]string, 0)
+		for _, lastAffected := range lastAffectedVersions[rangeType] {
+			for _, fixed := range fixedVersions[rangeType] {
+				n, err := comparator.Compare(lastAffected, fixed)
+				if err != nil {
+					return nil, err
+				}
+				if n != 0 || containsString(reported, fixed) {
+					continue
+				}
+				reported = append(reported, fixed)
+				conflicts = append(conflicts, fmt.Sprintf("%s version %s is both fixed and last_affected", rangeType, fixed))
+			}
+		}
+	}
+
+	for _, merged := range normalized.Ranges {
+		if merged.Type == RangeTypeGit {
+			continue
+		}
+		comparator, err := x.versionComparator(merged.Type)
+		if err != nil {
+			return nil, err
+		}
+		intervals, err := mergeIntervals(comparator, mergedIntervals[merged])
+		if err != nil {
+			return nil, err
+		}
+		if merged.Events, err = intervals.ToEvents(); err != nil {
+			return nil, err
+		}
+	}
+	if len(conflicts) != 0 {
+		conflictError := &RangeConflictError{Conflicts: conflicts}
+		if x.Package != nil {
+			conflictError.Package = x.Package.Name
+		}
+		return nil, conflictError
+	}
+
+	// 所有的区间都被 limit 裁掉的范围就不需要了
+	ranges := normalized.Ranges[:0]
+	for _, r := range normalized.Ranges {
+		if r.Type == RangeTypeGit || len(r.Events) != 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	normalized.Ranges = ranges
+
+	normalized.Versions = normalizeVersions(x.Package, x.Versions)
+	return &normalized, nil
+}
+
+// Normalize 规范化所有的影响范围，返回一个新的 OsvSchema ，@see Affected.Normalize
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) Normalize() (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	normalized := *x
+	normalized.Affected = make(AffectedSlice[EcosystemSpecific, DatabaseSpecific], 0, len(x.Affected))
+	for _, affected := range x.Affected {
+		if affected == nil {
+			continue
+		}
+		n, err := affected.Normalize()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", x.ID, err)
+		}
+		normalized.Affected = append(normalized.Affected, n)
+	}
+	return &normalized, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 范围中除了事件之外的字段序列化之后的结果，相同的范围才能合并
+func rangeMergeKey[DatabaseSpecific any](r *Range[DatabaseSpecific]) (string, error) {
+	withoutEvents := *r
+	withoutEvents.Events = nil
+	keyBytes, err := json.Marshal(withoutEvents)
+	if err != nil {
+		return "", err
+	}
+	return string(keyBytes), nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// 去掉GIT类型的范围中重复的事件，保持原来的顺序
+func normalizeGitRange[DatabaseSpecific any](r *Range[DatabaseSpecific]) *Range[DatabaseSpecific] {
+	normalized := *r
+	normalized.Events = dedupeEvents(r.Events)
+	return &normalized
+}
+
+// 去掉重复的事件和nil，保持原来的顺序
+func dedupeEvents(events Events) Events {
+	result := make(Events, 0, len(events))
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		key := eventKind(event) + ":" + event.version()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, event)
+	}
+	return result
+}
+
+// 判断是否已经有仓库和事件都相同的GIT类型的范围了
+func containsEqualGitRange[DatabaseSpecific any](ranges []*Range[DatabaseSpecific], git *Range[DatabaseSpecific]) bool {
+	for _, r := range ranges {
+		if r.Type != RangeTypeGit || repoKey(r.Repo) != repoKey(git.Repo) || len(r.Events) != len(git.Events) {
+			continue
+		}
+		keys := make(map[string]bool)
+		for _, event := range r.Events {
+			keys[eventKind(event)+":"+event.version()] = true
+		}
+		equal := true
+		for _, event := range git.Events {
+			if !keys[eventKind(event)+":"+event.version()] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return true
+		}
+	}
+	return false
+}
+
+// 版本去重，能比较的时候按照从小到大排列，不能比较的时候保持原来的顺序
+func normalizeVersions(pkg *Package, versions []string) []string {
+	if versions == nil {
+		return nil
+	}
+	result := make([]string, 0, len(versions))
+	seen := make(map[string]bool)
+	for _, version := range versions {
+		if !seen[version] {
+			seen[version] = true
+			result = append(result, version)
+		}
+	}
+	if pkg == nil {
+		return result
+	}
+	comparator, err := GetVersionComparator(pkg.Ecosystem)
+	if err != nil {
+		return result
+	}
+	sorted := append([]string(nil), result...)
+	if err := SortVersions(comparator, sorted); err != nil {
+		return result
+	}
+	return sorted
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,87 @@
+package osv_schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffected_Normalize(t *testing.T) {
+	affected := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeEcosystem, Events: Events{{Fixed: "2.0"}, {Introduced: "1.0"}}},
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "1.5"}, {Fixed: "2.5"}}},
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "2.5"}, {LastAffected: "2.7"}, {Introduced: "3.0"}, {Limit: "3.2"}}},
+			{Type: RangeTypeGit, Repo: "https://github.com/foo/foo", Events: Events{{Introduced: "0"}, {Fixed: "abc"}, {Fixed: "abc"}}},
+			{Type: RangeTypeGit, Repo: "https://github.com/foo/foo/", Events: Events{{Fixed: "abc"}, {Introduced: "0"}}},
+		},
+		Versions: []string{"1.10", "1.2", "1.10"},
+	}
+	normalized, err := affected.Normalize()
+	assert.Nil(t, err)
+	assert.Len(t, affected.Ranges, 5)
+	assert.Len(t, normalized.Ranges, 2)
+	assert.Equal(t, Events{{Introduced: "1.0"}, {LastAffected: "2.7"}, {Introduced: "3.0"}, {Fixed: "3.2"}}, normalized.Ranges[0].Events)
+	assert.Equal(t, Events{{Introduced: "0"}, {Fixed: "abc"}}, normalized.Ranges[1].Events)
+	assert.Equal(t, []string{"1.2", "1.10"}, normalized.Versions)
+
+	contradictory := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemNpm, Name: "bar"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeSemver, Events: Events{{Introduced: "0"}, {Fixed: "1.0.0"}}},
+			{Type: RangeTypeSemver, Events: Events{{Introduced: "0"}, {LastAffected: "1.0.0"}}},
+			{Type: RangeTypeSemver, Events: Events{{Introduced: "2.0.0"}, {Fixed: "2.0.0"}}},
+		},
+	}
+	_, err = contradictory.Normalize()
+	var conflictError *RangeConflictError
+	assert.True(t, errors.As(err, &conflictError))
+	assert.Equal(t, "bar", conflictError.Package)
+	assert.Len(t, conflictError.Conflicts, 2)
+
+	// 除了事件之外的字段不同的范围不会被合并
+	separate := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "1.0"}, {Fixed: "2.0"}}, DatabaseSpecific: map[string]any{"source": "a"}},
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "1.5"}, {Fixed: "3.0"}}, DatabaseSpecific: map[string]any{"source": "b"}},
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "2.0"}, {Fixed: "2.5"}}, DatabaseSpecific: map[string]any{"source": "a"}},
+		},
+	}
+	normalized, err = separate.Normalize()
+	assert.Nil(t, err)
+	assert.Len(t, normalized.Ranges, 2)
+	assert.Equal(t, map[string]any{"source": "a"}, normalized.Ranges[0].DatabaseSpecific)
+	assert.Equal(t, Events{{Introduced: "1.0"}, {Fixed: "2.5"}}, normalized.Ranges[0].Events)
+	assert.Equal(t, map[string]any{"source": "b"}, normalized.Ranges[1].DatabaseSpecific)
+
+	// 按照比较器判断 fixed 和 last_affected 是否是同一个版本
+	contradictory = &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "0"}, {Fixed: "1.0"}}},
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "0"}, {LastAffected: "1.0.0"}}},
+		},
+	}
+	_, err = contradictory.Normalize()
+	assert.True(t, errors.As(err, &conflictError))
+	assert.Equal(t, []string{"ECOSYSTEM version 1.0 is both fixed and last_affected"}, conflictError.Conflicts)
+
+	// 区间结束之后多余的 fixed 不是矛盾，前面没有任何 introduced 的 fixed 才是
+	redundant := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemNpm, Name: "a"},
+		Ranges:  []*Range[any]{{Type: RangeTypeSemver, Events: Events{{Introduced: "1.0.0"}, {Fixed: "1.2.0"}, {Fixed: "1.3.0"}}}},
+	}
+	normalized, err = redundant.Normalize()
+	assert.Nil(t, err)
+	assert.Equal(t, Events{{Introduced: "1.0.0"}, {Fixed: "1.2.0"}}, normalized.Ranges[0].Events)
+	contradictory = &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemNpm, Name: "a"},
+		Ranges:  []*Range[any]{{Type: RangeTypeSemver, Events: Events{{Fixed: "1.0.0"}, {Introduced: "1.2.0"}}}},
+	}
+	_, err = contradictory.Normalize()
+	assert.True(t, errors.As(err, &conflictError))
+	assert.Equal(t, []string{"fixed 1.0.0 has no introduced event before it"}, conflictError.Conflicts)
+}
@@ -4,7 +4,8 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
-	"fmt"
+	"net/url"
+	"strings"
 )
 
 // ------------------------------------------------ ---------------------------------------------------------------------
@@ -14,6 +15,7 @@ type References []*Reference
 var _ sql.Scanner = &References{}
 var _ driver.Valuer = &References{}
 
+// FilterByType 过滤出给定类型的引用，类型不区分大小写
 func (x References) FilterByType(referenceTypes ...ReferenceType) References {
 
 	if len(referenceTypes) == 0 {
@@ -22,25 +24,64 @@ func (x References) FilterByType(referenceTypes ...ReferenceType) References {
 
 	referenceTypeSet := make(map[ReferenceType]struct{}, 0)
 	for _, r := range referenceTypes {
-		referenceTypeSet[r] = struct{}{}
+		referenceTypeSet[ParseReferenceType(string(r))] = struct{}{}
 	}
 
 	slice := make([]*Reference, 0)
 	for _, r := range x {
-		if _, exists := referenceTypeSet[r.Type]; exists {
+		if _, exists := referenceTypeSet[ParseReferenceType(string(r.Type))]; exists {
 			slice = append(slice, r)
 		}
 	}
 	return slice
 }
 
+// Normalize 返回一个新的引用列表，其中的类型被规范化为大写，链接使用 NormalizeReferenceURL 规范化，原来的引用不会被修改
+func (x References) Normalize() References {
+	if x == nil {
+		return nil
+	}
+	slice := make([]*Reference, 0, len(x))
+	for _, r := range x {
+		if r == nil {
+			continue
+		}
+		normalized := *r
+		normalized.Type = ParseReferenceType(string(r.Type))
+		normalized.URL = NormalizeReferenceURL(r.URL)
+		slice = append(slice, &normalized)
+	}
+	return slice
+}
+
+// Deduplicate 去除重复的引用，类型相同并且规范化之后的链接相同的引用被认为是重复的，保留第一次出现的那个，返回新的列表
+func (x References) Deduplicate() References {
+	if x == nil {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(x))
+	slice := make([]*Reference, 0, len(x))
+	for _, r := range x {
+		if r == nil {
+			continue
+		}
+		key := string(ParseReferenceType(string(r.Type))) + " " + NormalizeReferenceURL(r.URL)
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		seen[key] = struct{}{}
+		slice = append(slice, r)
+	}
+	return slice
+}
+
 func (x *References) Scan(src any) error {
 	if src == nil {
 		return nil
 	}
 	bytes, ok := src.([]byte)
 	if !ok {
-		return fmt.Errorf("scan error")
+		return wrapScanError(src, x)
 	}
 	if len(bytes) == 0 {
 		return nil
@@ -93,19 +134,48 @@ const (
 	// ReferenceTypeIntroduced A source code browser link to the introduction of the vulnerability (e.g., a GitHub commit)
 	// Note that the introduced type is meant for viewing by people using web browsers. Programs interested in analyzing the
 	// exact commit range would do better to use the GIT-typed affected[].ranges entries (described above).
-	ReferenceTypeIntroduced ReferenceType = "introduced"
+	ReferenceTypeIntroduced ReferenceType = "INTRODUCED"
+
+	// ReferenceTypeGit A link to a Git repository, Schema 1.5.0 中新增
+	ReferenceTypeGit ReferenceType = "GIT"
 
 	// ReferenceTypePackage A home web page for the package.
 	ReferenceTypePackage ReferenceType = "PACKAGE"
 
 	// ReferenceTypeEvidence A demonstration of the validity of a vulnerability claim, e.g. app.any.run replaying the
 	// exploitation of the vulnerability.
-	ReferenceTypeEvidence ReferenceType = "evidence"
+	ReferenceTypeEvidence ReferenceType = "EVIDENCE"
 
 	// ReferenceTypeWeb A web page of some unspecified kind.
 	ReferenceTypeWeb ReferenceType = "WEB"
 )
 
+// ParseReferenceType 大小写不敏感的解析引用类型，返回规范化之后的类型，比如 introduced 返回 INTRODUCED
+func ParseReferenceType(referenceType string) ReferenceType {
+	return ReferenceType(strings.ToUpper(strings.TrimSpace(referenceType)))
+}
+
+// IsValid 判断是否是规范中定义的引用类型
+func (x ReferenceType) IsValid() bool {
+	switch x {
+	case ReferenceTypeAdvisory, ReferenceTypeArticle, ReferenceTypeDetection, ReferenceTypeDiscussion, ReferenceTypeReport,
+		ReferenceTypeFix, ReferenceTypeIntroduced, ReferenceTypeGit, ReferenceTypePackage, ReferenceTypeEvidence, ReferenceTypeWeb:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmarshalJSON 反序列化的时候大小写不敏感，统一转为规范化的大写形式
+func (x *ReferenceType) UnmarshalJSON(bytes []byte) error {
+	var s string
+	if err := json.Unmarshal(bytes, &s); err != nil {
+		return err
+	}
+	*x = ParseReferenceType(s)
+	return nil
+}
+
 // ------------------------------------------------- --------------------------------------------------------------------
 
 // Reference
@@ -122,6 +192,9 @@ type Reference struct {
 
 	// 具体的引用链接
 	URL string `mapstructure:"url" json:"url" yaml:"url" db:"url" bson:"url" gorm:"column:url"`
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Reference{}
@@ -144,3 +217,114 @@ func (x *Reference) Scan(src any) error {
 	}
 	return json.Unmarshal(bytes, &x)
 }
+
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type referenceJson Reference
+
+func (x *Reference) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*referenceJson)(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Reference) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(referenceJson(x), x.UnknownFields)
+}
+
+// NormalizedURL 返回规范化之后的链接，@see NormalizeReferenceURL
+func (x *Reference) NormalizedURL() string {
+	return NormalizeReferenceURL(x.URL)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// NormalizeReferenceURL 规范化引用的链接，用于比较和去重：
+// 1. scheme和host转为小写，http升级为https，去掉默认端口
+// 2. 去掉路径末尾的 /
+// 3. GitHub的链接去掉 www. 前缀，仓库的链接去掉仓库名的 .git 后缀，owner和仓库名转为小写，
+// commits/<sha> 统一为 commit/<sha> ，去掉提交链接的 .patch 和 .diff 后缀，去掉PR链接末尾的 files 和 commits
+// 无法解析的链接只去掉首尾的空白
+func NormalizeReferenceURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme == "http" {
+		u.Scheme = "https"
+	}
+	host := strings.ToLower(u.Host)
+	host = strings.TrimSuffix(strings.TrimSuffix(host, ":443"), ":80")
+	u.Host = host
+
+	path := u.Path
+	for len(path) > 1 && strings.HasSuffix(path, "/") {
+		path = path[:len(path)-1]
+	}
+	if path == "/" {
+		path = ""
+	}
+
+	if u.Host == "github.com" || u.Host == "www.github.com" {
+		u.Host = "github.com"
+		path = normalizeGitHubPath(path)
+	}
+	u.Path = path
+	u.RawPath = ""
+	return u.String()
+}
+
+// GitHub上不是仓库的链接的第一段路径，比如 /advisories/GHSA-xxxx-xxxx-xxxx ，这些链接的路径原样保留
+var githubReservedPaths = map[string]struct{}{
+	"about": {}, "advisories": {}, "apps": {}, "collections": {}, "enterprise": {}, "enterprises": {}, "events": {},
+	"explore": {}, "features": {}, "issues": {}, "login": {}, "marketplace": {}, "notifications": {}, "orgs": {},
+	"organizations": {}, "pulls": {}, "search": {}, "security": {}, "settings": {}, "site": {}, "sponsors": {},
+	"topics": {}, "trending": {}, "users": {},
+}
+
+// 规范化GitHub链接的路径，只有仓库的链接才会把owner和仓库名转为小写
+func normalizeGitHubPath(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	if len(segments) < 2 {
+		return path
+	}
+	if _, ok := githubReservedPaths[strings.ToLower(segments[0])]; ok {
+		return path
+	}
+	segments[0] = strings.ToLower(segments[0])
+	segments[1] = strings.ToLower(strings.TrimSuffix(segments[1], ".git"))
+	if len(segments) >= 4 {
+		switch segments[2] {
+		case "commit", "commits":
+			// commits/<branch> 是提交历史而不是单个提交，只处理提交哈希
+			hash := strings.TrimSuffix(strings.TrimSuffix(segments[3], ".patch"), ".diff")
+			if len(segments) == 4 && isCommitHash(hash) {
+				segments[2] = "commit"
+				segments[3] = strings.ToLower(hash)
+			}
+		case "pull":
+			if len(segments) == 5 && (segments[4] == "files" || segments[4] == "commits") {
+				segments = segments[:4]
+			}
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// 判断是否是Git的提交哈希，允许缩写的哈希，至少7位
+func isCommitHash(s string) bool {
+	if len(s) < 7 || len(s) > 64 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
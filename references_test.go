@@ -0,0 +1,43 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReferenceType_UnmarshalJSON(t *testing.T) {
+	r, err := UnmarshalFromJson[any, any]([]byte(`{"id": "OSV-1", "references": [
+		{"type": "introduced", "url": "https://github.com/a/b/commit/1234567"},
+		{"type": "Evidence", "url": "https://example.com"},
+		{"type": "GIT", "url": "https://github.com/a/b"}
+	]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, ReferenceTypeIntroduced, r.References[0].Type)
+	assert.Len(t, r.References.FilterByType(ReferenceTypeEvidence, ReferenceTypeGit), 2)
+}
+
+func TestNormalizeReferenceURL(t *testing.T) {
+	assert.Equal(t, "https://github.com/tensorflow/tensorflow/commit/8741e57d163a079db05a7107a7609af70931def4",
+		NormalizeReferenceURL("http://www.github.com/TensorFlow/tensorflow.git/commits/8741E57D163A079DB05A7107A7609AF70931DEF4.patch"))
+	assert.Equal(t, "https://github.com/a/b/commits/main", NormalizeReferenceURL("https://github.com/a/b/commits/main/"))
+	assert.Equal(t, "https://github.com/a/b/pull/1", NormalizeReferenceURL("https://github.com/a/b/pull/1/files"))
+	assert.Equal(t, "https://example.com/advisory?id=1", NormalizeReferenceURL("HTTP://Example.com:80/advisory/?id=1"))
+	assert.Equal(t, "https://github.com/advisories/GHSA-vxv8-r8q2-63xw", NormalizeReferenceURL("https://github.com/advisories/GHSA-vxv8-r8q2-63xw"))
+	assert.Equal(t, "https://github.com/orgs/Foo/teams/Bar", NormalizeReferenceURL("https://www.github.com/orgs/Foo/teams/Bar/"))
+
+	references := References{
+		{Type: "WEB", URL: "https://github.com/a/b/"},
+		{Type: "web", URL: "http://github.com/a/b"},
+		{Type: "PACKAGE", URL: "https://github.com/a/b"},
+	}
+	assert.Len(t, references.Deduplicate(), 2)
+	assert.Equal(t, "https://github.com/a/b", references.Normalize()[0].URL)
+
+	references = References{{Type: "ADVISORY", URL: "https://github.com/advisories/GHSA-vxv8-r8q2-63xw"}}
+	assert.Equal(t, "https://github.com/advisories/GHSA-vxv8-r8q2-63xw", references.Normalize()[0].URL)
+}
+
+func TestReferences_Scan(t *testing.T) {
+	references := References{}
+	assert.EqualError(t, references.Scan("not bytes"), wrapScanError("not bytes", &references).Error())
+}
@@ -6,11 +6,33 @@ import (
 	"encoding/json"
 )
 
+// Related 与当前漏洞相关但是并不是同一个漏洞的其它漏洞的编号
 type Related []string
 
 var _ sql.Scanner = &Related{}
 var _ driver.Valuer = &Related{}
 
+// Filter 过滤出需要的编号
+func (x Related) Filter(filterFunc func(related string) bool) Related {
+	slice := make([]string, 0)
+	for _, related := range x {
+		if filterFunc(related) {
+			slice = append(slice, related)
+		}
+	}
+	return slice
+}
+
+// ByDatabase 过滤出属于给定漏洞库的编号，比如 ByDatabase("GHSA") ，漏洞库的前缀不区分大小写
+func (x Related) ByDatabase(prefix string) Related {
+	return filterVulnIDsByDatabase(x, prefix)
+}
+
+// VulnIDs 解析所有的编号，无法识别或者格式不正确的编号会被忽略
+func (x Related) VulnIDs() []*VulnID {
+	return parseVulnIDs(x)
+}
+
 func (x *Related) Scan(src any) error {
 	if src == nil {
 		return nil
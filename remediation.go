@@ -0,0 +1,384 @@
+package osv_schema
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// ErrNoFixAvailable 找不到不受影响的更高版本，比如范围没有上界，或者只知道 last_affected 而又没有提供已发布的版本列表
+var ErrNoFixAvailable = errors.New("no fix available")
+
+// 受影响的版本的集合：SEMVER、ECOSYSTEM类型的范围转换成的区间，加上 Versions 中明确列出的版本。
+// 和 Range.ContainsVersion 一样，SEMVER类型的范围总是使用语义化版本比较，ECOSYSTEM类型的范围使用包管理器的比较器
+type affectedVersionSet struct {
+
+	// 包管理器的比较器，用来比较列出的版本和已发布的版本
+	comparator VersionComparator
+
+	// 按照范围的类型分组的区间，每组使用自己的比较器合并
+	groups []*affectedIntervalGroup
+
+	// Versions 中列出的版本，按照比较器判断是否相等，"1.0" 和 "1.0.0" 在PEP 440中是同一个版本
+	versions []string
+}
+
+type affectedIntervalGroup struct {
+	rangeType  RangeType
+	comparator VersionComparator
+	intervals  VersionIntervals
+}
+
+func newAffectedVersionSet(comparator VersionComparator) *affectedVersionSet {
+	return &affectedVersionSet{comparator: comparator}
+}
+
+// 把一个影响范围中的版本加入到集合中，GIT类型的范围会被忽略
+func addAffectedVersions[EcosystemSpecific, DatabaseSpecific any](set *affectedVersionSet, affected *Affected[EcosystemSpecific, DatabaseSpecific]) error {
+	for _, r := range affected.Ranges {
+		if r == nil || r.Type == RangeTypeGit {
+			continue
+		}
+		group := set.group(r.Type)
+		// 和 Range.ContainsVersion 一样，容忍事件中的矛盾
+		intervals, _, err := eventsToIntervals(group.comparator, r.Events)
+		if err != nil {
+			return err
+		}
+		merged, err := mergeIntervals(group.comparator, append(group.intervals, intervals...))
+		if err != nil {
+			return err
+		}
+		group.intervals = merged
+	}
+	for _, version := range affected.Versions {
+		listed, err := set.isListed(version)
+		if err != nil {
+			return err
+		}
+		if !listed {
+			set.versions = append(set.versions, version)
+		}
+	}
+	return nil
+}
+
+// 获取范围的类型对应的分组，没有的话创建一个
+func (x *affectedVersionSet) group(rangeType RangeType) *affectedIntervalGroup {
+	for _, group := range x.groups {
+		if group.rangeType == rangeType {
+			return group
+		}
+	}
+	group := &affectedIntervalGroup{rangeType: rangeType, comparator: x.comparator, intervals: VersionIntervals{}}
+	if rangeType == RangeTypeSemver {
+		group.comparator = SemverComparator
+	}
+	x.groups = append(x.groups, group)
+	return group
+}
+
+// 是否有SEMVER、ECOSYSTEM类型的范围
+func (x *affectedVersionSet) hasRanges() bool {
+	for _, group := range x.groups {
+		if len(group.intervals) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// 版本是否在 Versions 中列出，按照比较器判断是否相等，比较器无法解析的版本按照字符串比较
+func (x *affectedVersionSet) isListed(version string) (bool, error) {
+	for _, listed := range x.versions {
+		if listed == version {
+			return true, nil
+		}
+		if n, err := x.comparator.Compare(listed, version); err == nil && n == 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// 版本是否在任意一个范围中
+func (x *affectedVersionSet) inRanges(version string) (bool, error) {
+	interval, err := x.containingInterval(version)
+	return interval != nil, err
+}
+
+// 找到包含版本的区间以及区间所在的分组，不在任何一个范围中的时候返回nil
+func (x *affectedVersionSet) containingInterval(version string) (*VersionInterval, error) {
+	for _, group := range x.groups {
+		for _, interval := range group.intervals {
+			ok, err := interval.Contains(group.comparator, version)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				return interval, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (x *affectedVersionSet) contains(version string) (bool, error) {
+	listed, err := x.isListed(version)
+	if err != nil || listed {
+		return listed, err
+	}
+	return x.inRanges(version)
+}
+
+// 所有范围合并之后的区间，不同类型的范围统一使用包管理器的比较器合并
+func (x *affectedVersionSet) mergedIntervals() (VersionIntervals, error) {
+	intervals := VersionIntervals{}
+	for _, group := range x.groups {
+		intervals = append(intervals, group.intervals...)
+	}
+	return mergeIntervals(x.comparator, intervals)
+}
+
+// 找到大于等于installed的最小的不受影响的版本，@see Affected.MinimalFixedVersion
+func (x *affectedVersionSet) minimalUnaffected(installed string, published []string) (string, error) {
+	affected, err := x.contains(installed)
+	if err != nil {
+		return "", err
+	}
+	if !affected {
+		return installed, nil
+	}
+
+	if len(published) != 0 {
+		// 包管理器的版本列表中经常会有无法解析的版本，跳过这些版本，找不到修复版本的时候在错误中报告
+		sorted := make([]string, 0, len(published))
+		skipped := make([]string, 0)
+		for _, version := range published {
+			if _, err := x.comparator.Compare(version, version); err != nil {
+				skipped = append(skipped, version)
+				continue
+			}
+			sorted = append(sorted, version)
+		}
+		if err := SortVersions(x.comparator, sorted); err != nil {
+			return "", err
+		}
+		for _, version := range sorted {
+			n, err := x.comparator.Compare(version, installed)
+			if err != nil {
+				return "", err
+			}
+			if n <= 0 {
+				continue
+			}
+			affected, err := x.contains(version)
+			if err != nil {
+				return "", err
+			}
+			if !affected {
+				return version, nil
+			}
+		}
+		if len(skipped) != 0 {
+			return "", fmt.Errorf("%w: every published version above %s is affected, unparseable versions %q are skipped", ErrNoFixAvailable, installed, skipped)
+		}
+		return "", fmt.Errorf("%w: every published version above %s is affected", ErrNoFixAvailable, installed)
+	}
+
+	// 修复的版本可能落在另一个类型的范围中，继续往上找，每个区间最多经过一次
+	candidate := installed
+	for steps := 0; steps <= x.intervalCount(); steps++ {
+		interval, err := x.containingInterval(candidate)
+		if err != nil {
+			return "", err
+		}
+		if interval == nil {
+			listed, err := x.isListed(candidate)
+			if err != nil {
+				return "", err
+			}
+			if !listed {
+				return candidate, nil
+			}
+			if candidate == installed {
+				// 只在 Versions 中列出的版本没有修复的信息
+				return "", fmt.Errorf("%w: %s is listed as affected without a range", ErrNoFixAvailable, installed)
+			}
+			return "", fmt.Errorf("%w: fixed version %s is listed as affected", ErrNoFixAvailable, candidate)
+		}
+		switch {
+		case interval.Upper == "":
+			return "", fmt.Errorf("%w: %s is affected by an unbounded range", ErrNoFixAvailable, candidate)
+		case interval.UpperInclusive:
+			return "", fmt.Errorf("%w: %s is the last affected version, the published versions are needed to find the next one", ErrNoFixAvailable, interval.Upper)
+		}
+		candidate = interval.Upper
+	}
+	return "", fmt.Errorf("%w: can not find a version above %s outside of the ranges", ErrNoFixAvailable, installed)
+}
+
+func (x *affectedVersionSet) intervalCount() int {
+	count := 0
+	for _, group := range x.groups {
+		count += len(group.intervals)
+	}
+	return count
+}
+
+// 获取包的版本比较器，包管理器不支持的时候如果只有SEMVER类型的范围的话也可以使用语义化版本比较
+func packageVersionComparator[EcosystemSpecific, DatabaseSpecific any](pkg *Package, affected ...*Affected[EcosystemSpecific, DatabaseSpecific]) (VersionComparator, error) {
+	comparator, err := GetVersionComparator(pkg.Ecosystem)
+	if err == nil {
+		return comparator, nil
+	}
+	for _, a := range affected {
+		for _, r := range a.Ranges {
+			if r != nil && r.Type != RangeTypeGit && r.Type != RangeTypeSemver {
+				return nil, err
+			}
+		}
+	}
+	return SemverComparator, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// MinimalFixedVersion 计算安装的版本应该升级到的最小的不受影响的版本，安装的版本本身不受影响的时候直接返回安装的版本。
+// 多个范围会合并起来考虑，修复版本落在另一个范围中的时候会继续往上找。
+// published 是包的所有已发布的版本，可以为空：为空的时候返回范围的 fixed 版本，只有 last_affected 的范围无法确定下一个版本，返回 ErrNoFixAvailable ；
+// 不为空的时候返回已发布的版本中大于安装的版本的最小的不受影响的版本，比较器无法解析的已发布的版本会被跳过。找不到的时候返回 ErrNoFixAvailable
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) MinimalFixedVersion(installed string, published []string) (string, error) {
+	set, err := x.affectedVersionSet()
+	if err != nil {
+		return "", err
+	}
+	return set.minimalUnaffected(installed, published)
+}
+
+// MinimalFixedVersion 计算给定的包的安装的版本应该升级到的最小的不受这个漏洞影响的版本，会同时考虑这个包的所有影响范围，
+// 不影响这个包的时候直接返回安装的版本。和 IsPackageVersionAffected 一样ecosystem需要完全相同，
+// 比如查询 Debian 不会匹配到 Debian:11 的影响范围。和 OsvSchemaSlice.MinimalUpgrade 一样，已撤回的漏洞不影响任何版本，直接返回安装的版本，
+// @see Affected.MinimalFixedVersion
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) MinimalFixedVersion(ecosystem Ecosystem, name, installed string, published []string) (string, error) {
+	if x.IsWithdrawn() {
+		return installed, nil
+	}
+	return minimalUpgrade(OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]{x}, ecosystem, name, installed, published)
+}
+
+// MinimalUpgrade 计算给定的包的安装的版本应该升级到的最小的版本，升级之后不受集合中任何一个漏洞的影响，
+// 已撤回的漏洞默认不参与计算，ecosystem需要完全相同，@see OsvSchema.MinimalFixedVersion
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) MinimalUpgrade(ecosystem Ecosystem, name, installed string, published []string, options ...*QueryOptions) (string, error) {
+	return minimalUpgrade(x.FilterByPackage(ecosystem, name, options...), ecosystem, name, installed, published)
+}
+
+func minimalUpgrade[EcosystemSpecific, DatabaseSpecific any](slice OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific], ecosystem Ecosystem, name, installed string, published []string) (string, error) {
+	affectedSlice := make([]*Affected[EcosystemSpecific, DatabaseSpecific], 0)
+	for _, osvSchema := range slice {
+		for _, affected := range osvSchema.Affected {
+			if affected != nil && affected.Package != nil && affected.Package.Ecosystem == ecosystem && affected.Package.Name == name {
+				affectedSlice = append(affectedSlice, affected)
+			}
+		}
+	}
+	if len(affectedSlice) == 0 {
+		return installed, nil
+	}
+
+	comparator, err := packageVersionComparator(&Package{Ecosystem: ecosystem, Name: name}, affectedSlice...)
+	if err != nil {
+		return "", err
+	}
+	set := newAffectedVersionSet(comparator)
+	for _, affected := range affectedSlice {
+		if err := addAffectedVersions(set, affected); err != nil {
+			return "", err
+		}
+	}
+	return set.minimalUnaffected(installed, published)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// AffectedIntervals 把所有SEMVER和ECOSYSTEM类型的范围合并为按照版本从小到大排列的不重叠的受影响的区间， Versions 中列出的版本不包含在内
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) AffectedIntervals() (VersionIntervals, error) {
+	set, err := x.affectedVersionSet()
+	if err != nil {
+		return nil, err
+	}
+	return set.mergedIntervals()
+}
+
+// SafeIntervals 返回不受影响的区间，即受影响的区间的补集，比如 <1.2.0 || >=1.4.5 ， Versions 中列出的版本也会被排除掉。
+// 补集的下界可能是不包含的（比如 last_affected 之后的版本），这时转换为事件会返回错误，可以转换为约束
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) SafeIntervals() (VersionIntervals, error) {
+	set, err := x.affectedVersionSet()
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := set.mergedIntervals()
+	if err != nil {
+		return nil, err
+	}
+	safe := intervals.Complement()
+	for _, version := range set.versions {
+		if safe, err = intersectIntervals(set.comparator, safe, exceptVersion(version)); err != nil {
+			return nil, err
+		}
+	}
+	return safe, nil
+}
+
+// SafeConstraint 返回用包所在的包管理器的约束语法表示的不受影响的版本，@see Affected.SafeIntervals
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) SafeConstraint() (string, error) {
+	safe, err := x.SafeIntervals()
+	if err != nil {
+		return "", err
+	}
+	syntax, err := GetConstraintSyntax(x.Package.Ecosystem)
+	if err != nil {
+		return "", err
+	}
+	if len(safe) == 0 {
+		return "", fmt.Errorf("%w: every version of %s is affected", ErrNoFixAvailable, x.Package.Name)
+	}
+	return formatConstraint(syntax, safe)
+}
+
+// SafeVersions 返回给定的版本中不受影响的版本，保持原来的顺序， Versions 中列出的版本也认为是受影响的
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) SafeVersions(versions []string) ([]string, error) {
+	set, err := x.affectedVersionSet()
+	if err != nil {
+		return nil, err
+	}
+	safe := make([]string, 0, len(versions))
+	for _, version := range versions {
+		affected, err := set.contains(version)
+		if err != nil {
+			return nil, err
+		}
+		if !affected {
+			safe = append(safe, version)
+		}
+	}
+	return safe, nil
+}
+
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) affectedVersionSet() (*affectedVersionSet, error) {
+	if x.Package == nil {
+		return nil, fmt.Errorf("affected has no package")
+	}
+	comparator, err := packageVersionComparator(x.Package, x)
+	if err != nil {
+		return nil, err
+	}
+	set := newAffectedVersionSet(comparator)
+	if err := addAffectedVersions(set, x); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,154 @@
+package osv_schema
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffected_MinimalFixedVersion(t *testing.T) {
+	affected := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemNpm, Name: "foo"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeSemver, Events: Events{{Introduced: "1.0.0"}, {Fixed: "1.2.0"}}},
+			{Type: RangeTypeSemver, Events: Events{{Introduced: "1.2.0"}, {Fixed: "1.3.0"}, {Introduced: "2.0.0"}, {LastAffected: "2.1.0"}}},
+		},
+	}
+	fixed, err := affected.MinimalFixedVersion("1.1.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.3.0", fixed)
+
+	fixed, err = affected.MinimalFixedVersion("0.9.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.9.0", fixed)
+
+	_, err = affected.MinimalFixedVersion("2.0.5", nil)
+	assert.True(t, errors.Is(err, ErrNoFixAvailable))
+
+	fixed, err = affected.MinimalFixedVersion("2.0.5", []string{"2.2.0", "1.3.0", "2.1.0", "2.1.1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "2.1.1", fixed)
+}
+
+func TestAffected_MinimalFixedVersionComparator(t *testing.T) {
+	// 列出的版本按照比较器判断是否相等，PEP 440中 1.5 和 1.5.0 是同一个版本
+	affected := &Affected[any, any]{
+		Package:  &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges:   []*Range[any]{{Type: RangeTypeEcosystem, Events: Events{{Introduced: "1.0"}, {Fixed: "1.5.0"}}}},
+		Versions: []string{"1.5"},
+	}
+	_, err := affected.MinimalFixedVersion("1.2", nil)
+	assert.True(t, errors.Is(err, ErrNoFixAvailable))
+	fixed, err := affected.MinimalFixedVersion("1.2", []string{"1.5.0", "1.6"})
+	assert.Nil(t, err)
+	assert.Equal(t, "1.6", fixed)
+
+	// SEMVER类型的范围总是使用语义化版本比较，和 IsVersionAffected 的结果一致
+	affected = &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges:  []*Range[any]{{Type: RangeTypeSemver, Events: Events{{Introduced: "0"}, {Fixed: "1.0.0+1"}}}},
+	}
+	isAffected, err := affected.IsVersionAffected("1.0.0")
+	assert.Nil(t, err)
+	assert.False(t, isAffected)
+	fixed, err = affected.MinimalFixedVersion("1.0.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0.0", fixed)
+
+	// 无法解析的已发布的版本会被跳过
+	affected = &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "a"},
+		Ranges:  []*Range[any]{{Type: RangeTypeEcosystem, Events: Events{{Introduced: "0"}, {LastAffected: "1.0"}}}},
+	}
+	fixed, err = affected.MinimalFixedVersion("1.0", []string{"1.0", "2.0", "not-a-version!!"})
+	assert.Nil(t, err)
+	assert.Equal(t, "2.0", fixed)
+	_, err = affected.MinimalFixedVersion("1.0", []string{"1.0", "not-a-version!!"})
+	assert.True(t, errors.Is(err, ErrNoFixAvailable))
+	assert.Contains(t, err.Error(), "not-a-version!!")
+}
+
+func TestOsvSchemaSlice_MinimalUpgrade(t *testing.T) {
+	newRecord := func(id string, events Events) *OsvSchema[any, any] {
+		return &OsvSchema[any, any]{ID: id, Affected: AffectedSlice[any, any]{{
+			Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+			Ranges:  []*Range[any]{{Type: RangeTypeEcosystem, Events: events}},
+		}}}
+	}
+	withdrawn := newRecord("PYSEC-3", Events{{Introduced: "0"}})
+	withdrawnAt := time.Now()
+	withdrawn.Withdrawn = &withdrawnAt
+	slice := OsvSchemaSlice[any, any]{
+		newRecord("PYSEC-1", Events{{Introduced: "0"}, {Fixed: "1.5"}}),
+		newRecord("PYSEC-2", Events{{Introduced: "1.4"}, {Fixed: "1.6.1"}}),
+		withdrawn,
+	}
+
+	version, err := slice.MinimalUpgrade(EcosystemPyPI, "foo", "1.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.6.1", version)
+
+	version, err = slice[0].MinimalFixedVersion(EcosystemPyPI, "foo", "1.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5", version)
+
+	_, err = slice.MinimalUpgrade(EcosystemPyPI, "foo", "1.0", nil, &QueryOptions{IncludeWithdrawn: true})
+	assert.True(t, errors.Is(err, ErrNoFixAvailable))
+
+	// 单条记录的查询和集合的查询一样不考虑已撤回的漏洞
+	version, err = withdrawn.MinimalFixedVersion(EcosystemPyPI, "foo", "1.0", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "1.0", version)
+	affected, err := withdrawn.IsPackageVersionAffected(EcosystemPyPI, "foo", "1.0")
+	assert.Nil(t, err)
+	assert.False(t, affected)
+	affectedSlice, err := slice.FilterByPackageVersion(EcosystemPyPI, "foo", "1.0", &QueryOptions{IncludeWithdrawn: true})
+	assert.Nil(t, err)
+	assert.Len(t, affectedSlice, 2)
+}
+
+func TestAffected_SafeIntervals(t *testing.T) {
+	affected := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "1.2.0"}, {Fixed: "1.4.5"}, {Introduced: "2.0"}, {LastAffected: "2.1"}}},
+		},
+		Versions: []string{"3.0"},
+	}
+	safe, err := affected.SafeIntervals()
+	assert.Nil(t, err)
+	assert.Equal(t, "<1.2.0 || >=1.4.5 <2.0 || >2.1 <3.0 || >3.0", safe.String())
+	_, err = safe.ToEvents()
+	assert.NotNil(t, err)
+
+	constraint, err := affected.SafeConstraint()
+	assert.True(t, errors.Is(err, ErrInexactConstraint))
+	assert.Equal(t, "", constraint)
+
+	affected.Package.Ecosystem = EcosystemNpm
+	constraint, err = affected.SafeConstraint()
+	assert.Nil(t, err)
+	assert.Equal(t, "<1.2.0 || >=1.4.5 <2.0 || >2.1 <3.0 || >3.0", constraint)
+
+	versions, err := affected.SafeVersions([]string{"1.0.0", "1.3.0", "1.4.5", "2.1.0", "2.2.0", "3.0.0"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.4.5", "2.2.0"}, versions)
+
+	// 只有 Versions 的时候列出的版本也不是安全的
+	onlyVersions := &Affected[any, any]{Package: &Package{Ecosystem: EcosystemNpm, Name: "foo"}, Versions: []string{"3.0.0"}}
+	constraint, err = onlyVersions.SafeConstraint()
+	assert.Nil(t, err)
+	assert.Equal(t, "<3.0.0 || >3.0.0", constraint)
+	onlyVersions.Package.Ecosystem = EcosystemPyPI
+	onlyVersions.Versions = []string{"3.0"}
+	_, err = onlyVersions.SafeConstraint()
+	assert.True(t, errors.Is(err, ErrInexactConstraint))
+	versions, err = onlyVersions.SafeVersions([]string{"2.9", "3.0.0", "3.1"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2.9", "3.1"}, versions)
+
+	assert.Equal(t, "*", VersionIntervals{}.Complement().String())
+	assert.Equal(t, "", allVersions().Complement().String())
+}
@@ -0,0 +1,327 @@
+package osv_schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// IsValidSchemaVersion 判断是否是合法的Schema版本号，版本号格式为 major.minor.patch
+func IsValidSchemaVersion(version string) bool {
+	_, err := parseSchemaVersion(version)
+	return err == nil
+}
+
+// CompareSchemaVersion 比较两个Schema版本号，a < b 返回负数，a == b 返回0，a > b 返回正数，不合法的版本号返回错误
+func CompareSchemaVersion(a, b string) (int, error) {
+	versionA, err := parseSchemaVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	versionB, err := parseSchemaVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range versionA {
+		if versionA[i] != versionB[i] {
+			return versionA[i] - versionB[i], nil
+		}
+	}
+	return 0, nil
+}
+
+// 解析 major.minor.patch 格式的版本号，patch可以省略
+func parseSchemaVersion(version string) ([3]int, error) {
+	var result [3]int
+	split := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(split) < 2 || len(split) > 3 {
+		return result, fmt.Errorf("invalid schema version %q", version)
+	}
+	for i, s := range split {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return result, fmt.Errorf("invalid schema version %q", version)
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
+// 比较两个已知合法的版本号
+func mustCompareSchemaVersion(a, b string) int {
+	n, err := CompareSchemaVersion(a, b)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// UsedSchemaFeatures 返回这条记录用到的所有需要特定版本Schema才能表示的特性
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) UsedSchemaFeatures() []SchemaFeature {
+	used := make(map[SchemaFeature]struct{})
+	if len(x.Upstream) != 0 {
+		used[SchemaFeatureUpstream] = struct{}{}
+	}
+	for _, credits := range x.Credits {
+		if credits == nil {
+			continue
+		}
+		used[SchemaFeatureCredits] = struct{}{}
+		if credits.Type != "" {
+			used[SchemaFeatureCreditsType] = struct{}{}
+		}
+	}
+	for _, reference := range x.References {
+		if reference == nil {
+			continue
+		}
+		switch strings.ToUpper(string(reference.Type)) {
+		case "INTRODUCED":
+			used[SchemaFeatureReferenceTypeIntroduced] = struct{}{}
+		case "EVIDENCE":
+			used[SchemaFeatureReferenceTypeEvidence] = struct{}{}
+		case "GIT":
+			used[SchemaFeatureReferenceTypeGit] = struct{}{}
+		}
+	}
+	severities := append(SeveritySlice{}, x.Severity...)
+	for _, affected := range x.Affected {
+		if affected == nil {
+			continue
+		}
+		severities = append(severities, affected.Severity...)
+		if affected.Package != nil && affected.Package.Ecosystem.Base() == EcosystemGit {
+			used[SchemaFeatureEcosystemGit] = struct{}{}
+		}
+		for _, r := range affected.Ranges {
+			if r == nil {
+				continue
+			}
+			for _, event := range r.Events {
+				if event != nil && event.IsLastAffected() {
+					used[SchemaFeatureLastAffected] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, severity := range severities {
+		if severity == nil {
+			continue
+		}
+		switch severity.Type {
+		case SeverityTypeCVSS4:
+			used[SchemaFeatureSeverityTypeCVSS4] = struct{}{}
+		case SeverityTypeUbuntu:
+			used[SchemaFeatureSeverityTypeUbuntu] = struct{}{}
+		}
+	}
+	features := make([]SchemaFeature, 0, len(used))
+	for _, feature := range SchemaFeatures() {
+		if _, ok := used[feature]; ok {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// MinimumSchemaVersion 根据这条记录用到的特性推断出能够表示它的最低的Schema版本
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) MinimumSchemaVersion() string {
+	version := "1.0.0"
+	for _, feature := range x.UsedSchemaFeatures() {
+		if mustCompareSchemaVersion(feature.Since(), version) > 0 {
+			version = feature.Since()
+		}
+	}
+	return version
+}
+
+// DetectSchemaVersion 检测这条记录的Schema版本，优先使用记录中声明的版本，如果没有声明或者声明的版本不合法，则根据用到的特性推断
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) DetectSchemaVersion() string {
+	if IsValidSchemaVersion(x.SchemaVersion) {
+		return x.SchemaVersion
+	}
+	return x.MinimumSchemaVersion()
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// UpgradeSchema 把记录原地升级到当前支持的Schema版本 CurrentSchemaVersion ：
+// 1. 把引用类型、严重级别类型、贡献者类型的大小写规范化
+// 2. 把 1.0 之前的格式中的顶层 package 和 affects 字段转换为 affected
+// 3. 把 schema_version 设置为 CurrentSchemaVersion
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) UpgradeSchema() error {
+	if IsValidSchemaVersion(x.SchemaVersion) && mustCompareSchemaVersion(x.SchemaVersion, CurrentSchemaVersion) > 0 {
+		return fmt.Errorf("can not upgrade schema version %s to older version %s", x.SchemaVersion, CurrentSchemaVersion)
+	}
+
+	for _, reference := range x.References {
+		if reference != nil {
+			reference.Type = ParseReferenceType(string(reference.Type))
+		}
+	}
+	severities := append(SeveritySlice{}, x.Severity...)
+	for _, affected := range x.Affected {
+		if affected != nil {
+			severities = append(severities, affected.Severity...)
+		}
+	}
+	for _, severity := range severities {
+		if severity == nil {
+			continue
+		}
+		switch strings.ToUpper(string(severity.Type)) {
+		case string(SeverityTypeCVSS2), string(SeverityTypeCVSS3), string(SeverityTypeCVSS4):
+			severity.Type = SeverityType(strings.ToUpper(string(severity.Type)))
+		case strings.ToUpper(string(SeverityTypeUbuntu)):
+			severity.Type = SeverityTypeUbuntu
+		}
+	}
+	for _, credits := range x.Credits {
+		if credits != nil {
+			credits.Type = CreditsType(strings.ToUpper(string(credits.Type)))
+		}
+	}
+
+	if err := x.upgradeLegacyAffects(); err != nil {
+		return err
+	}
+
+	x.SchemaVersion = CurrentSchemaVersion
+	return nil
+}
+
+// 1.0 之前的格式中，受影响的包放在顶层的 package 字段中，受影响的范围放在 affects 字段中，范围没有 events 而是直接写 introduced 和 fixed
+//
+//	"package": {"ecosystem": "PyPI", "name": "tensorflow"},
+//	"affects": {"ranges": [{"type": "ECOSYSTEM", "introduced": "2.8.0", "fixed": "2.8.1"}], "versions": ["2.8.0"]}
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) upgradeLegacyAffects() error {
+	packageBytes, hasPackage := x.UnknownFields["package"]
+	affectsBytes, hasAffects := x.UnknownFields["affects"]
+	if !hasPackage && !hasAffects {
+		return nil
+	}
+	if len(x.Affected) != 0 {
+		return fmt.Errorf("record %s has both legacy package/affects fields and affected field", x.ID)
+	}
+
+	affected := &Affected[EcosystemSpecific, DatabaseSpecific]{}
+	if hasPackage {
+		affected.Package = &Package{}
+		if err := json.Unmarshal(packageBytes, affected.Package); err != nil {
+			return fmt.Errorf("can not convert legacy package field: %w", err)
+		}
+	}
+	if hasAffects {
+		affects := struct {
+			Ranges []struct {
+				Type       RangeType `json:"type"`
+				Repo       string    `json:"repo"`
+				Introduced string    `json:"introduced"`
+				Fixed      string    `json:"fixed"`
+			} `json:"ranges"`
+			Versions []string `json:"versions"`
+		}{}
+		if err := json.Unmarshal(affectsBytes, &affects); err != nil {
+			return fmt.Errorf("can not convert legacy affects field: %w", err)
+		}
+		for _, legacyRange := range affects.Ranges {
+			r := &Range[DatabaseSpecific]{Type: legacyRange.Type, Repo: legacyRange.Repo}
+			introduced := legacyRange.Introduced
+			if introduced == "" {
+				introduced = "0"
+			}
+			r.Events = append(r.Events, &Event{Introduced: introduced})
+			if legacyRange.Fixed != "" {
+				r.Events = append(r.Events, &Event{Fixed: legacyRange.Fixed})
+			}
+			affected.Ranges = append(affected.Ranges, r)
+		}
+		affected.Versions = affects.Versions
+	}
+
+	x.Affected = AffectedSlice[EcosystemSpecific, DatabaseSpecific]{affected}
+	delete(x.UnknownFields, "package")
+	delete(x.UnknownFields, "affects")
+	return nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// DowngradeSchema 把记录原地降级到给定的Schema版本，给只支持旧版本Schema的消费者使用：
+// 1. 删除目标版本还不支持的 upstream 字段、CVSS_V4 和 Ubuntu 类型的严重级别、贡献者的类型或者整个贡献者字段
+// 2. 目标版本还不支持的引用类型会被转换为 WEB
+// 3. 如果记录中用到了目标版本无法表示的会影响匹配结果的特性，比如 last_affected 和 GIT 类型的包管理器，则返回错误，记录不会被修改
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) DowngradeSchema(targetVersion string) error {
+	compare, err := CompareSchemaVersion(targetVersion, CurrentSchemaVersion)
+	if err != nil {
+		return err
+	}
+	if compare > 0 {
+		return fmt.Errorf("can not downgrade to schema version %s newer than %s", targetVersion, CurrentSchemaVersion)
+	}
+
+	unsupported := make(map[SchemaFeature]struct{})
+	for _, feature := range x.UsedSchemaFeatures() {
+		if mustCompareSchemaVersion(feature.Since(), targetVersion) > 0 {
+			unsupported[feature] = struct{}{}
+		}
+	}
+	for _, feature := range []SchemaFeature{SchemaFeatureLastAffected, SchemaFeatureEcosystemGit} {
+		if _, ok := unsupported[feature]; ok {
+			return fmt.Errorf("record %s uses %s which can not be represented in schema version %s", x.ID, feature, targetVersion)
+		}
+	}
+
+	if _, ok := unsupported[SchemaFeatureUpstream]; ok {
+		x.Upstream = nil
+	}
+	if _, ok := unsupported[SchemaFeatureCredits]; ok {
+		x.Credits = nil
+	} else if _, ok := unsupported[SchemaFeatureCreditsType]; ok {
+		for _, credits := range x.Credits {
+			if credits != nil {
+				credits.Type = ""
+			}
+		}
+	}
+	for _, reference := range x.References {
+		if reference == nil {
+			continue
+		}
+		feature := SchemaFeature("references.type." + strings.ToUpper(string(reference.Type)))
+		if _, ok := unsupported[feature]; ok {
+			reference.Type = ReferenceTypeWeb
+		}
+	}
+	keepSeverity := func(severities SeveritySlice) SeveritySlice {
+		if severities == nil {
+			return nil
+		}
+		slice := make(SeveritySlice, 0, len(severities))
+		for _, severity := range severities {
+			if severity == nil {
+				continue
+			}
+			if _, ok := unsupported[SchemaFeature("severity.type."+string(severity.Type))]; !ok {
+				slice = append(slice, severity)
+			}
+		}
+		return slice
+	}
+	x.Severity = keepSeverity(x.Severity)
+	for _, affected := range x.Affected {
+		if affected != nil {
+			affected.Severity = keepSeverity(affected.Severity)
+		}
+	}
+
+	x.SchemaVersion = targetVersion
+	return nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
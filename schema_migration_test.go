@@ -0,0 +1,33 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestOsvSchema_UpgradeSchema(t *testing.T) {
+	r, err := UnmarshalFromJson[any, any]([]byte(`{
+		"id": "OSV-2020-1",
+		"modified": "2021-01-01T00:00:00Z",
+		"package": {"ecosystem": "OSS-Fuzz", "name": "foo"},
+		"affects": {"ranges": [{"type": "GIT", "repo": "https://example.com/foo", "introduced": "a", "fixed": "b"}]},
+		"references": [{"type": "introduced", "url": "https://example.com/commit/a"}]
+	}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5.0", r.DetectSchemaVersion())
+
+	assert.Nil(t, r.UpgradeSchema())
+	assert.Equal(t, CurrentSchemaVersion, r.SchemaVersion)
+	assert.Empty(t, r.UnknownFields)
+	assert.Equal(t, ReferenceTypeIntroduced, r.References[0].Type)
+	assert.Equal(t, "foo", r.Affected[0].Package.Name)
+	assert.Equal(t, Events{{Introduced: "a"}, {Fixed: "b"}}, r.Affected[0].Ranges[0].Events)
+
+	assert.Nil(t, r.DowngradeSchema("1.4.0"))
+	assert.Equal(t, "1.4.0", r.SchemaVersion)
+	assert.Equal(t, ReferenceTypeWeb, r.References[0].Type)
+
+	r.Affected[0].Ranges[0].Events = append(r.Affected[0].Ranges[0].Events, &Event{LastAffected: "c"})
+	assert.NotNil(t, r.DowngradeSchema("1.1.0"))
+	assert.Equal(t, "1.4.0", r.SchemaVersion)
+}
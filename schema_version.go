@@ -0,0 +1,81 @@
+package osv_schema
+
+// CurrentSchemaVersion 当前支持的OSV Schema的版本
+// 参考文档： https://github.com/ossf/osv-schema/blob/main/CHANGELOG.md
+const CurrentSchemaVersion = "1.7.0"
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// SchemaFeature 表示OSV Schema在某个版本中新增的特性，用于判断一条记录至少需要哪个版本的Schema才能表示
+type SchemaFeature string
+
+const (
+
+	// SchemaFeatureLastAffected 范围事件中的 last_affected
+	SchemaFeatureLastAffected SchemaFeature = "last_affected"
+
+	// SchemaFeatureCredits 顶层的 credits 字段
+	SchemaFeatureCredits SchemaFeature = "credits"
+
+	// SchemaFeatureCreditsType credits 中的 type 字段
+	SchemaFeatureCreditsType SchemaFeature = "credits.type"
+
+	// SchemaFeatureReferenceTypeIntroduced 引用类型 INTRODUCED
+	SchemaFeatureReferenceTypeIntroduced SchemaFeature = "references.type.INTRODUCED"
+
+	// SchemaFeatureReferenceTypeEvidence 引用类型 EVIDENCE
+	SchemaFeatureReferenceTypeEvidence SchemaFeature = "references.type.EVIDENCE"
+
+	// SchemaFeatureReferenceTypeGit 引用类型 GIT
+	SchemaFeatureReferenceTypeGit SchemaFeature = "references.type.GIT"
+
+	// SchemaFeatureSeverityTypeCVSS4 严重级别类型 CVSS_V4
+	SchemaFeatureSeverityTypeCVSS4 SchemaFeature = "severity.type.CVSS_V4"
+
+	// SchemaFeatureSeverityTypeUbuntu 严重级别类型 Ubuntu
+	SchemaFeatureSeverityTypeUbuntu SchemaFeature = "severity.type.Ubuntu"
+
+	// SchemaFeatureEcosystemGit 包管理器类型 GIT
+	SchemaFeatureEcosystemGit SchemaFeature = "affected.package.ecosystem.GIT"
+
+	// SchemaFeatureUpstream 顶层的 upstream 字段
+	SchemaFeatureUpstream SchemaFeature = "upstream"
+)
+
+// 每个特性是在哪个版本的Schema中引入的，按引入的版本排序
+var schemaFeatures = []struct {
+	feature SchemaFeature
+	since   string
+}{
+	{SchemaFeatureLastAffected, "1.2.0"},
+	{SchemaFeatureCredits, "1.3.0"},
+	{SchemaFeatureCreditsType, "1.4.0"},
+	{SchemaFeatureReferenceTypeIntroduced, "1.5.0"},
+	{SchemaFeatureReferenceTypeEvidence, "1.5.0"},
+	{SchemaFeatureReferenceTypeGit, "1.5.0"},
+	{SchemaFeatureSeverityTypeCVSS4, "1.6.0"},
+	{SchemaFeatureSeverityTypeUbuntu, "1.6.0"},
+	{SchemaFeatureEcosystemGit, "1.6.0"},
+	{SchemaFeatureUpstream, "1.7.0"},
+}
+
+// Since 返回此特性是在哪个版本的Schema中引入的，未知的特性返回空字符串
+func (x SchemaFeature) Since() string {
+	for _, item := range schemaFeatures {
+		if item.feature == x {
+			return item.since
+		}
+	}
+	return ""
+}
+
+// SchemaFeatures 返回所有已知的特性，按引入的版本排序
+func SchemaFeatures() []SchemaFeature {
+	features := make([]SchemaFeature, 0, len(schemaFeatures))
+	for _, item := range schemaFeatures {
+		features = append(features, item.feature)
+	}
+	return features
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -25,6 +25,15 @@ func (x SeveritySlice) GetCVSS3() *Severity {
 	return nil
 }
 
+func (x SeveritySlice) GetCVSS4() *Severity {
+	for _, s := range x {
+		if s.Type == SeverityTypeCVSS4 {
+			return s
+		}
+	}
+	return nil
+}
+
 func (x SeveritySlice) GetCVSS2() *Severity {
 	for _, s := range x {
 		if s.Type == SeverityTypeCVSS2 {
@@ -73,6 +82,12 @@ const (
 
 	// SeverityTypeCVSS3 CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:C/C:H/I:N/A:N
 	SeverityTypeCVSS3 SeverityType = "CVSS_V3"
+
+	// SeverityTypeCVSS4 CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N ，Schema 1.6.0 中新增
+	SeverityTypeCVSS4 SeverityType = "CVSS_V4"
+
+	// SeverityTypeUbuntu Ubuntu的优先级，比如 high ，只在 ecosystem 为 Ubuntu 的时候使用，Schema 1.6.0 中新增
+	SeverityTypeUbuntu SeverityType = "Ubuntu"
 )
 
 // Severity
@@ -90,6 +105,9 @@ type Severity struct {
 
 	score *float64
 	err   error
+
+	// 反序列化时遇到的当前结构体不认识的字段，序列化时会原样写回
+	UnknownFields UnknownFields `mapstructure:"-" json:"-" yaml:"-" db:"-" bson:"-" gorm:"-"`
 }
 
 var _ sql.Scanner = &Severity{}
@@ -150,4 +168,20 @@ func (x *Severity) Scan(src any) error {
 	return json.Unmarshal(bytes, &x)
 }
 
+// 序列化和反序列化时使用的类型，不带自定义的JSON方法，避免递归调用
+type severityJson Severity
+
+func (x *Severity) UnmarshalJSON(bytes []byte) error {
+	unknownFields, err := unmarshalJsonWithUnknownFields(bytes, (*severityJson)(x))
+	if err != nil {
+		return err
+	}
+	x.UnknownFields = unknownFields
+	return nil
+}
+
+func (x Severity) MarshalJSON() ([]byte, error) {
+	return marshalJsonWithUnknownFields(severityJson(x), x.UnknownFields)
+}
+
 // ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,230 @@
+package osv_schema
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// StreamFormat 流式读取的数据的格式
+type StreamFormat string
+
+const (
+
+	// StreamFormatAuto 根据第一个非空白字符自动检测，是 [ 的话认为是JSON数组，否则认为是JSON Lines
+	StreamFormatAuto StreamFormat = ""
+
+	// StreamFormatJsonArray 一个JSON数组，数组的每个元素是一条漏洞记录
+	StreamFormatJsonArray StreamFormat = "json-array"
+
+	// StreamFormatJsonLines JSON Lines格式，每一行是一条漏洞记录，空行会被忽略
+	StreamFormatJsonLines StreamFormat = "jsonl"
+)
+
+// StreamOptions 流式读取的选项，传nil的话使用默认选项
+type StreamOptions struct {
+
+	// 数据的格式，默认自动检测
+	Format StreamFormat
+
+	// 遇到无法解析的记录时是否继续读取后面的记录，默认遇到错误就停止。
+	// JSON数组中出现语法错误时无法定位下一条记录，即使设置了也会停止
+	ContinueOnError bool
+
+	// 是否使用严格模式，遇到不认识的字段时返回 *UnknownFieldsError ，@see UnmarshalFromJsonStrict
+	Strict bool
+}
+
+// RecordError 流式读取时某一条记录的错误
+type RecordError struct {
+
+	// 记录的序号，从0开始，JSON Lines中的空行不计数
+	Index int
+
+	// 记录在输入中的字节偏移量
+	Offset int64
+
+	Err error
+}
+
+var _ error = &RecordError{}
+
+func (x *RecordError) Error() string {
+	return fmt.Sprintf("record %d at offset %d: %s", x.Index, x.Offset, x.Err.Error())
+}
+
+func (x *RecordError) Unwrap() error {
+	return x.Err
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// StreamDecoder 从 io.Reader 中逐条读取漏洞记录，同一时间只会在内存中保留一条记录，适合处理很大的导出文件
+type StreamDecoder[EcosystemSpecific, DatabaseSpecific any] struct {
+	reader  *bufio.Reader
+	closer  io.Closer
+	options StreamOptions
+	format  StreamFormat
+	started bool
+
+	// JSON数组使用标准库的流式解析
+	decoder *json.Decoder
+
+	// JSON数组是decoder开始读取的位置，JSON Lines是当前读取到的位置
+	offset int64
+
+	// 下一条记录的序号
+	index int
+
+	// 无法继续读取时的错误，之后调用 Next 都会返回这个错误
+	err error
+}
+
+// NewStreamDecoder 创建一个流式读取漏洞记录的解码器
+func NewStreamDecoder[EcosystemSpecific, DatabaseSpecific any](reader io.Reader, options *StreamOptions) *StreamDecoder[EcosystemSpecific, DatabaseSpecific] {
+	x := &StreamDecoder[EcosystemSpecific, DatabaseSpecific]{reader: bufio.NewReader(reader)}
+	if options != nil {
+		x.options = *options
+	}
+	x.format = x.options.Format
+	return x
+}
+
+// OpenStreamFile 打开文件流式读取其中的漏洞记录，读取完之后需要调用 Close 关闭文件。
+// 压缩过的文件会根据魔数自动解压，这时 RecordError.Offset 是解压之后的偏移量
+func OpenStreamFile[EcosystemSpecific, DatabaseSpecific any](path string, options *StreamOptions) (*StreamDecoder[EcosystemSpecific, DatabaseSpecific], error) {
+	file, err := openFileDecompressed(path)
+	if err != nil {
+		return nil, err
+	}
+	x := NewStreamDecoder[EcosystemSpecific, DatabaseSpecific](file, options)
+	x.closer = file
+	return x, nil
+}
+
+// Close 关闭底层的文件，不是通过 OpenStreamFile 创建的话什么也不做
+func (x *StreamDecoder[EcosystemSpecific, DatabaseSpecific]) Close() error {
+	if x.closer == nil {
+		return nil
+	}
+	return x.closer.Close()
+}
+
+// Next 读取下一条记录，读取完的时候返回 io.EOF 。
+// 记录无法解析的时候返回 *RecordError ，设置了 StreamOptions.ContinueOnError 的话可以继续调用 Next 读取后面的记录
+func (x *StreamDecoder[EcosystemSpecific, DatabaseSpecific]) Next() (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	if x.err != nil {
+		return nil, x.err
+	}
+	if !x.started {
+		x.started = true
+		if err := x.start(); err != nil {
+			x.err = err
+			return nil, err
+		}
+	}
+	if x.format == StreamFormatJsonArray {
+		return x.nextArrayElement()
+	}
+	return x.nextLine()
+}
+
+// 跳过开头的空白，检测格式
+func (x *StreamDecoder[EcosystemSpecific, DatabaseSpecific]) start() error {
+	for {
+		b, err := x.reader.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			x.offset++
+			continue
+		}
+		if err := x.reader.UnreadByte(); err != nil {
+			return err
+		}
+		if x.format == StreamFormatAuto {
+			x.format = StreamFormatJsonLines
+			if b == '[' {
+				x.format = StreamFormatJsonArray
+			}
+		}
+		break
+	}
+
+	if x.format != StreamFormatJsonArray {
+		return nil
+	}
+	x.decoder = json.NewDecoder(x.reader)
+	token, err := x.decoder.Token()
+	if err != nil {
+		return &RecordError{Index: x.index, Offset: x.offset, Err: err}
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return &RecordError{Index: x.index, Offset: x.offset, Err: fmt.Errorf("expect a JSON array, got %v", token)}
+	}
+	return nil
+}
+
+func (x *StreamDecoder[EcosystemSpecific, DatabaseSpecific]) nextArrayElement() (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	if !x.decoder.More() {
+		if _, err := x.decoder.Token(); err != nil {
+			x.err = &RecordError{Index: x.index, Offset: x.offset + x.decoder.InputOffset(), Err: err}
+			return nil, x.err
+		}
+		x.err = io.EOF
+		return nil, x.err
+	}
+
+	offset := x.offset + x.decoder.InputOffset()
+	var raw json.RawMessage
+	if err := x.decoder.Decode(&raw); err != nil {
+		x.err = &RecordError{Index: x.index, Offset: offset, Err: err}
+		return nil, x.err
+	}
+	return x.decodeRecord(raw, x.offset+x.decoder.InputOffset()-int64(len(raw)))
+}
+
+func (x *StreamDecoder[EcosystemSpecific, DatabaseSpecific]) nextLine() (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	for {
+		line, err := x.reader.ReadBytes('\n')
+		offset := x.offset
+		x.offset += int64(len(line))
+		if err != nil && err != io.EOF {
+			x.err = &RecordError{Index: x.index, Offset: offset, Err: err}
+			return nil, x.err
+		}
+		trimmed := bytes.TrimLeft(line, " \t\r\n")
+		offset += int64(len(line) - len(trimmed))
+		trimmed = bytes.TrimRight(trimmed, " \t\r\n")
+		if len(trimmed) != 0 {
+			return x.decodeRecord(trimmed, offset)
+		}
+		if err == io.EOF {
+			x.err = io.EOF
+			return nil, x.err
+		}
+	}
+}
+
+func (x *StreamDecoder[EcosystemSpecific, DatabaseSpecific]) decodeRecord(raw []byte, offset int64) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	index := x.index
+	x.index++
+
+	r, err := decodeBytes[EcosystemSpecific, DatabaseSpecific](context.Background(), raw, &DecodeOptions{Strict: x.options.Strict})
+	if err != nil {
+		recordError := &RecordError{Index: index, Offset: offset, Err: err}
+		if !x.options.ContinueOnError {
+			x.err = recordError
+		}
+		return nil, recordError
+	}
+	return r, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,71 @@
+package osv_schema
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readAllRecords(decoder *StreamDecoder[any, any]) ([]string, []*RecordError) {
+	ids := make([]string, 0)
+	recordErrors := make([]*RecordError, 0)
+	for {
+		r, err := decoder.Next()
+		if err == io.EOF {
+			return ids, recordErrors
+		}
+		var recordError *RecordError
+		if errors.As(err, &recordError) {
+			recordErrors = append(recordErrors, recordError)
+			if len(recordErrors) > 10 {
+				return ids, recordErrors
+			}
+			continue
+		}
+		ids = append(ids, r.ID)
+	}
+}
+
+func TestStreamDecoder(t *testing.T) {
+	array := ` [{"id": "A-1"}, {"id": 1}, {"id": "A-3"}]`
+	ids, recordErrors := readAllRecords(NewStreamDecoder[any, any](strings.NewReader(array), &StreamOptions{ContinueOnError: true}))
+	assert.Equal(t, []string{"A-1", "A-3"}, ids)
+	assert.Len(t, recordErrors, 1)
+	assert.Equal(t, 1, recordErrors[0].Index)
+	assert.Equal(t, int64(17), recordErrors[0].Offset)
+
+	ids, recordErrors = readAllRecords(NewStreamDecoder[any, any](strings.NewReader(array), nil))
+	assert.Equal(t, []string{"A-1"}, ids)
+	assert.Len(t, recordErrors, 11)
+
+	lines := "{\"id\": \"L-1\"}\n\n{bad json\n  {\"id\": \"L-3\", \"foo\": 1}\n"
+	ids, recordErrors = readAllRecords(NewStreamDecoder[any, any](strings.NewReader(lines), &StreamOptions{ContinueOnError: true}))
+	assert.Equal(t, []string{"L-1", "L-3"}, ids)
+	assert.Len(t, recordErrors, 1)
+	assert.Equal(t, 1, recordErrors[0].Index)
+	assert.Equal(t, int64(15), recordErrors[0].Offset)
+
+	ids, recordErrors = readAllRecords(NewStreamDecoder[any, any](strings.NewReader(lines), &StreamOptions{ContinueOnError: true, Strict: true}))
+	assert.Equal(t, []string{"L-1"}, ids)
+	assert.Len(t, recordErrors, 2)
+	var unknownFieldsError *UnknownFieldsError
+	assert.True(t, errors.As(recordErrors[1], &unknownFieldsError))
+	assert.Equal(t, int64(27), recordErrors[1].Offset)
+
+	path := filepath.Join(t.TempDir(), "records.jsonl")
+	assert.Nil(t, os.WriteFile(path, []byte(lines), 0644))
+	decoder, err := OpenStreamFile[any, any](path, &StreamOptions{Format: StreamFormatJsonLines})
+	assert.Nil(t, err)
+	defer decoder.Close()
+	r, err := decoder.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "L-1", r.ID)
+
+	_, err = NewStreamDecoder[any, any](strings.NewReader("  "), nil).Next()
+	assert.Equal(t, io.EOF, err)
+}
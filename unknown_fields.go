@@ -0,0 +1,139 @@
+package osv_schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// UnknownFields 反序列化时遇到的当前结构体不认识的字段，比如新版本的Schema中增加的字段，序列化的时候会被原样写回去，保证数据不丢失
+type UnknownFields map[string]json.RawMessage
+
+// Keys 返回所有未知字段的名字，按字典序排列
+func (x UnknownFields) Keys() []string {
+	keys := make([]string, 0, len(x))
+	for key := range x {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// UnknownFieldsError 严格模式下反序列化遇到了未知字段时返回的错误
+type UnknownFieldsError struct {
+
+	// 未知字段的路径，比如 affected[0].ranges[0].events[1].foo
+	Paths []string
+}
+
+var _ error = &UnknownFieldsError{}
+
+func (x *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("unknown fields: %s", strings.Join(x.Paths, ", "))
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
+
+// 缓存每个类型的JSON字段名，key是reflect.Type，value是小写的字段名集合
+var jsonFieldNamesCache sync.Map
+
+// 获取结构体能够识别的JSON字段名，encoding/json匹配字段名时是大小写不敏感的，所以这里统一转为小写
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	if cached, ok := jsonFieldNamesCache.Load(t); ok {
+		return cached.(map[string]struct{})
+	}
+	names := make(map[string]struct{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = struct{}{}
+	}
+	jsonFieldNamesCache.Store(t, names)
+	return names
+}
+
+// 把JSON反序列化到v中，同时把v不认识的字段收集起来返回，v必须是指向结构体的指针
+func unmarshalJsonWithUnknownFields(jsonBytes []byte, v any) (UnknownFields, error) {
+	if bytes.Equal(bytes.TrimSpace(jsonBytes), []byte("null")) {
+		return nil, nil
+	}
+	if err := json.Unmarshal(jsonBytes, v); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(jsonBytes, &fields); err != nil {
+		return nil, err
+	}
+	knownNames := jsonFieldNames(reflect.TypeOf(v).Elem())
+	var unknownFields UnknownFields
+	for name, value := range fields {
+		if _, known := knownNames[strings.ToLower(name)]; known {
+			continue
+		}
+		if unknownFields == nil {
+			unknownFields = make(UnknownFields)
+		}
+		unknownFields[name] = value
+	}
+	return unknownFields, nil
+}
+
+// 把v序列化为JSON，并把未知字段按字典序追加到JSON对象的末尾，v必须是结构体
+func marshalJsonWithUnknownFields(v any, unknownFields UnknownFields) ([]byte, error) {
+	marshal, err := json.Marshal(v)
+	if err != nil || len(unknownFields) == 0 {
+		return marshal, err
+	}
+	knownNames := jsonFieldNames(reflect.TypeOf(v))
+	buff := bytes.NewBuffer(marshal[:len(marshal)-1])
+	hasField := len(marshal) > 2
+	for _, name := range unknownFields.Keys() {
+		if _, known := knownNames[strings.ToLower(name)]; known {
+			continue
+		}
+		nameBytes, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+		if hasField {
+			buff.WriteByte(',')
+		}
+		buff.Write(nameBytes)
+		buff.WriteByte(':')
+		buff.Write(unknownFields[name])
+		hasField = true
+	}
+	buff.WriteByte('}')
+	return buff.Bytes(), nil
+}
+
+// 收集未知字段的路径
+func appendUnknownFieldPaths(paths []string, prefix string, unknownFields UnknownFields) []string {
+	for _, name := range unknownFields.Keys() {
+		if prefix == "" {
+			paths = append(paths, name)
+		} else {
+			paths = append(paths, prefix+"."+name)
+		}
+	}
+	return paths
+}
+
+// ------------------------------------------------ ---------------------------------------------------------------------
@@ -1,11 +1,11 @@
 package osv_schema
 
 import (
+	"context"
 	"encoding/json"
-	"os"
 )
 
-// UnmarshalFromJson 从JSON字符串中反序列化
+// UnmarshalFromJson 从JSON字符串中反序列化，不认识的字段会被保存在各个结构体的UnknownFields中，序列化时会原样写回，不会丢失数据
 func UnmarshalFromJson[EcosystemSpecific, DatabaseSpecific any](jsonBytes []byte) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
 	r := &OsvSchema[EcosystemSpecific, DatabaseSpecific]{}
 	err := json.Unmarshal(jsonBytes, &r)
@@ -15,11 +15,34 @@ func UnmarshalFromJson[EcosystemSpecific, DatabaseSpecific any](jsonBytes []byte
 	return r, nil
 }
 
-// UnmarshalFromJsonFile UnmarshalFromJson 从JSOn文件中反序列化
+// UnmarshalFromJsonFile UnmarshalFromJson 从JSOn文件中反序列化，gzip等压缩过的文件会根据魔数自动解压，@see RegisterDecompressor
 func UnmarshalFromJsonFile[EcosystemSpecific, DatabaseSpecific any](jsonFilePath string) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
-	fileBytes, err := os.ReadFile(jsonFilePath)
+	reader, err := openFileDecompressed(jsonFilePath)
 	if err != nil {
 		return nil, err
 	}
-	return UnmarshalFromJson[EcosystemSpecific, DatabaseSpecific](fileBytes)
+	defer reader.Close()
+	return Decode[EcosystemSpecific, DatabaseSpecific](context.Background(), reader, nil)
+}
+
+// UnmarshalFromJsonStrict 从JSON字符串中反序列化，严格模式，遇到不认识的字段时返回 *UnknownFieldsError
+func UnmarshalFromJsonStrict[EcosystemSpecific, DatabaseSpecific any](jsonBytes []byte) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	r, err := UnmarshalFromJson[EcosystemSpecific, DatabaseSpecific](jsonBytes)
+	if err != nil {
+		return nil, err
+	}
+	if paths := r.UnknownFieldPaths(); len(paths) != 0 {
+		return nil, &UnknownFieldsError{Paths: paths}
+	}
+	return r, nil
+}
+
+// UnmarshalFromJsonFileStrict 从JSON文件中反序列化，严格模式，@see UnmarshalFromJsonStrict
+func UnmarshalFromJsonFileStrict[EcosystemSpecific, DatabaseSpecific any](jsonFilePath string) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	reader, err := openFileDecompressed(jsonFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return Decode[EcosystemSpecific, DatabaseSpecific](context.Background(), reader, &DecodeOptions{Strict: true})
 }
@@ -1,6 +1,7 @@
 package osv_schema
 
 import (
+	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -18,3 +19,23 @@ func TestUnmarshalFromJson(t *testing.T) {
 	assert.NotNil(t, json)
 
 }
+
+func TestUnmarshalFromJson_UnknownFields(t *testing.T) {
+	jsonBytes := []byte(`{"id":"GHSA-1","modified":"2023-01-01T00:00:00Z","upstream_new":["CVE-1"],"affected":[{"package":{"ecosystem":"npm","name":"a","x":1},"ranges":[{"type":"SEMVER","events":[{"introduced":"0","y":true}]}]}]}`)
+
+	r, err := UnmarshalFromJson[any, any](jsonBytes)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"upstream_new", "affected[0].package.x", "affected[0].ranges[0].events[0].y"}, r.UnknownFieldPaths())
+
+	marshal, err := json.Marshal(r)
+	assert.Nil(t, err)
+	again, err := UnmarshalFromJson[any, any](marshal)
+	assert.Nil(t, err)
+	assert.Equal(t, r.UnknownFieldPaths(), again.UnknownFieldPaths())
+	assert.Equal(t, json.RawMessage("true"), again.Affected[0].Ranges[0].Events[0].UnknownFields["y"])
+
+	_, err = UnmarshalFromJsonStrict[any, any](jsonBytes)
+	var unknownFieldsError *UnknownFieldsError
+	assert.ErrorAs(t, err, &unknownFieldsError)
+	assert.Len(t, unknownFieldsError.Paths, 3)
+}
@@ -0,0 +1,62 @@
+package osv_schema
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Upstream 当前漏洞是从哪些上游漏洞派生出来的，比如发行版的漏洞公告通常是从某个CVE派生出来的，Schema 1.7.0 中新增
+type Upstream []string
+
+var _ sql.Scanner = &Upstream{}
+var _ driver.Valuer = &Upstream{}
+
+// Filter 过滤出需要的上游漏洞编号
+func (x Upstream) Filter(filterFunc func(upstream string) bool) Upstream {
+	slice := make([]string, 0)
+	for _, upstream := range x {
+		if filterFunc(upstream) {
+			slice = append(slice, upstream)
+		}
+	}
+	return slice
+}
+
+// ByDatabase 过滤出属于给定漏洞库的编号，比如 ByDatabase("GHSA") ，漏洞库的前缀不区分大小写
+func (x Upstream) ByDatabase(prefix string) Upstream {
+	return filterVulnIDsByDatabase(x, prefix)
+}
+
+// VulnIDs 解析所有的编号，无法识别或者格式不正确的编号会被忽略
+func (x Upstream) VulnIDs() []*VulnID {
+	return parseVulnIDs(x)
+}
+
+func (x *Upstream) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	bytes, ok := src.([]byte)
+	if !ok {
+		return wrapScanError(src, x)
+	}
+	if len(bytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bytes, &x)
+}
+
+func (x Upstream) Value() (driver.Value, error) {
+	if len(x) == 0 {
+		return nil, nil
+	}
+	marshal, err := json.Marshal(x)
+	if err != nil {
+		return nil, err
+	}
+	if len(marshal) == 0 {
+		return nil, nil
+	}
+	return string(marshal), nil
+}
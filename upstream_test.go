@@ -0,0 +1,33 @@
+package osv_schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstream(t *testing.T) {
+	r, err := UnmarshalFromJson[any, any]([]byte(`{"id": "UBUNTU-CVE-2024-1234", "upstream": ["CVE-2024-1234", "GHSA-vxv8-r8q2-63xw", "not-an-id"]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, Upstream{"CVE-2024-1234", "GHSA-vxv8-r8q2-63xw", "not-an-id"}, r.Upstream)
+	assert.Empty(t, r.UnknownFields)
+
+	assert.Equal(t, Upstream{"CVE-2024-1234"}, r.Upstream.ByDatabase("cve"))
+	assert.Equal(t, Upstream{"not-an-id"}, r.Upstream.Filter(func(upstream string) bool {
+		return upstream == "not-an-id"
+	}))
+	ids := r.Upstream.VulnIDs()
+	assert.Len(t, ids, 2)
+	assert.Equal(t, "CVE", ids[0].Prefix())
+
+	value, err := r.Upstream.Value()
+	assert.Nil(t, err)
+	var scanned Upstream
+	assert.Nil(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, r.Upstream, scanned)
+
+	value, err = Upstream{}.Value()
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+	assert.NotNil(t, scanned.Scan("string"))
+}
@@ -0,0 +1,282 @@
+package osv_schema
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// VersionComparator 版本号比较器，不同的包管理器的版本号的比较规则是不一样的
+type VersionComparator interface {
+
+	// Compare 比较两个版本号，a < b 返回负数，a == b 返回0，a > b 返回正数，版本号不合法的时候返回错误
+	Compare(a, b string) (int, error)
+}
+
+// VersionComparatorFunc 把一个函数适配为 VersionComparator
+type VersionComparatorFunc func(a, b string) (int, error)
+
+var _ VersionComparator = VersionComparatorFunc(nil)
+
+func (x VersionComparatorFunc) Compare(a, b string) (int, error) {
+	return x(a, b)
+}
+
+// ErrUnsupportedEcosystem 包管理器没有注册版本号比较器
+var ErrUnsupportedEcosystem = errors.New("unsupported ecosystem")
+
+var (
+	versionComparatorsLock sync.RWMutex
+	versionComparators     = map[Ecosystem]VersionComparator{}
+)
+
+func init() {
+	for _, ecosystem := range []Ecosystem{EcosystemGo, EcosystemNpm, EcosystemCratesIo, EcosystemHex, EcosystemPub, EcosystemBitnami, EcosystemSwiftURL} {
+		RegisterVersionComparator(ecosystem, SemverComparator)
+	}
+	RegisterVersionComparator(EcosystemPyPI, PEP440Comparator)
+	RegisterVersionComparator(EcosystemMaven, MavenComparator)
+	RegisterVersionComparator(EcosystemRubyGems, RubyGemsComparator)
+	for _, ecosystem := range []Ecosystem{EcosystemDebian, EcosystemUbuntu} {
+		RegisterVersionComparator(ecosystem, DebianComparator)
+	}
+	for _, ecosystem := range []Ecosystem{EcosystemPackagist, EcosystemNuGet, EcosystemAlpine, EcosystemGitHubActions, EcosystemConanCenter,
+		EcosystemCRAN, EcosystemBioconductor, EcosystemHackage, EcosystemGHC, EcosystemChainguard, EcosystemWolfi, EcosystemMinimOS} {
+		RegisterVersionComparator(ecosystem, GenericComparator)
+	}
+}
+
+// RegisterVersionComparator 注册包管理器的版本号比较器，已经注册过的会被覆盖，ecosystem中的 :<RELEASE> 后缀会被忽略
+func RegisterVersionComparator(ecosystem Ecosystem, comparator VersionComparator) {
+	versionComparatorsLock.Lock()
+	defer versionComparatorsLock.Unlock()
+	versionComparators[ecosystem.Base()] = comparator
+}
+
+// GetVersionComparator 获取包管理器的版本号比较器，没有注册的话返回 ErrUnsupportedEcosystem
+func GetVersionComparator(ecosystem Ecosystem) (VersionComparator, error) {
+	versionComparatorsLock.RLock()
+	defer versionComparatorsLock.RUnlock()
+	comparator, ok := versionComparators[ecosystem.Base()]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedEcosystem, ecosystem)
+	}
+	return comparator, nil
+}
+
+// 获取范围应该使用的版本号比较器，SEMVER类型的范围总是使用语义化版本比较
+func getRangeVersionComparator(rangeType RangeType, ecosystem Ecosystem) (VersionComparator, error) {
+	switch rangeType {
+	case RangeTypeSemver:
+		return SemverComparator, nil
+	case RangeTypeEcosystem:
+		return GetVersionComparator(ecosystem)
+	default:
+		return nil, fmt.Errorf("range type %s can not be compared by version", rangeType)
+	}
+}
+
+// SortVersions 使用比较器对版本号原地排序，有版本号不合法的时候返回错误
+func SortVersions(comparator VersionComparator, versions []string) error {
+	var err error
+	sort.SliceStable(versions, func(i, j int) bool {
+		n, e := comparator.Compare(versions[i], versions[j])
+		if e != nil && err == nil {
+			err = e
+		}
+		return n < 0
+	})
+	return err
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 事件中的版本，introduced为0的时候表示最小的版本
+func (x *Event) version() string {
+	switch {
+	case x.IsIntroduced():
+		return x.Introduced
+	case x.IsFixed():
+		return x.Fixed
+	case x.IsLastAffected():
+		return x.LastAffected
+	default:
+		return x.Limit
+	}
+}
+
+// 比较两个事件的版本，introduced为0的事件总是最小的
+func compareEvents(comparator VersionComparator, a, b *Event) (int, error) {
+	aZero := a.IsIntroduced() && a.Introduced == "0"
+	bZero := b.IsIntroduced() && b.Introduced == "0"
+	switch {
+	case aZero && bZero:
+		return 0, nil
+	case aZero:
+		return -1, nil
+	case bZero:
+		return 1, nil
+	}
+	return comparator.Compare(a.version(), b.version())
+}
+
+// 返回按版本排好序的事件，不会修改原来的事件列表
+func sortEvents(comparator VersionComparator, events Events) (Events, error) {
+	sorted := make(Events, 0, len(events))
+	for _, event := range events {
+		if event != nil {
+			sorted = append(sorted, event)
+		}
+	}
+	var err error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		n, e := compareEvents(comparator, sorted[i], sorted[j])
+		if e != nil && err == nil {
+			err = e
+		}
+		return n < 0
+	})
+	return sorted, err
+}
+
+// ContainsVersion 使用给定的比较器判断版本是否在这个SEMVER或者ECOSYSTEM类型的范围中，算法和规范中给出的一致：
+// 事件按版本排序之后依次处理，大于等于 introduced 时进入受影响的状态，大于等于 fixed 或者大于 last_affected 时离开受影响的状态，
+// 大于等于 limit 的版本总是不受影响
+// 参考文档： https://ossf.github.io/osv-schema/#evaluation
+func (x *Range[DatabaseSpecific]) ContainsVersion(comparator VersionComparator, version string) (bool, error) {
+	if x.Type == RangeTypeGit {
+		return false, fmt.Errorf("range type %s can not be compared by version", x.Type)
+	}
+	events, err := sortEvents(comparator, x.Events)
+	if err != nil {
+		return false, err
+	}
+	affected := false
+	for _, event := range events {
+		switch {
+		case event.IsIntroduced():
+			if event.Introduced == "0" {
+				affected = true
+				continue
+			}
+			n, err := comparator.Compare(version, event.Introduced)
+			if err != nil {
+				return false, err
+			}
+			if n >= 0 {
+				affected = true
+			}
+		case event.IsFixed():
+			n, err := comparator.Compare(version, event.Fixed)
+			if err != nil {
+				return false, err
+			}
+			if n >= 0 {
+				affected = false
+			}
+		case event.IsLastAffected():
+			n, err := comparator.Compare(version, event.LastAffected)
+			if err != nil {
+				return false, err
+			}
+			if n > 0 {
+				affected = false
+			}
+		case event.IsLimit():
+			n, err := comparator.Compare(version, event.Limit)
+			if err != nil {
+				return false, err
+			}
+			if n >= 0 {
+				return false, nil
+			}
+		}
+	}
+	return affected, nil
+}
+
+// versionComparator 返回这个影响范围的ECOSYSTEM类型的范围应该使用的版本号比较器
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) versionComparator(rangeType RangeType) (VersionComparator, error) {
+	var ecosystem Ecosystem
+	if x.Package != nil {
+		ecosystem = x.Package.Ecosystem
+	}
+	return getRangeVersionComparator(rangeType, ecosystem)
+}
+
+// IsVersionAffected 判断包的某个版本是否受影响，版本在 Versions 中或者在任意一个SEMVER、ECOSYSTEM类型的范围中都认为是受影响的，
+// GIT类型的范围会被忽略，@see Affected.IsCommitAffected
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) IsVersionAffected(version string) (bool, error) {
+	for _, v := range x.Versions {
+		if v == version {
+			return true, nil
+		}
+	}
+	for _, r := range x.Ranges {
+		if r == nil || r.Type == RangeTypeGit {
+			continue
+		}
+		comparator, err := x.versionComparator(r.Type)
+		if err != nil {
+			return false, err
+		}
+		affected, err := r.ContainsVersion(comparator, version)
+		if err != nil {
+			return false, err
+		}
+		if affected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsPackageVersionAffected 判断给定的包的某个版本是否受这个漏洞影响，和 OsvSchemaSlice 的查询一样，已撤回的漏洞不影响任何版本
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) IsPackageVersionAffected(ecosystem Ecosystem, name, version string) (bool, error) {
+	if x.IsWithdrawn() {
+		return false, nil
+	}
+	return x.isPackageVersionAffected(ecosystem, name, version)
+}
+
+// 不考虑是否已撤回，判断给定的包的某个版本是否在影响范围中
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) isPackageVersionAffected(ecosystem Ecosystem, name, version string) (bool, error) {
+	for _, affected := range x.Affected {
+		if affected == nil || affected.Package == nil || affected.Package.Ecosystem != ecosystem || affected.Package.Name != name {
+			continue
+		}
+		ok, err := affected.IsVersionAffected(version)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FilterByPackageVersion 过滤出影响到给定的包的某个版本的漏洞，已撤回的漏洞默认会被排除掉
+func (x OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific]) FilterByPackageVersion(ecosystem Ecosystem, name, version string, options ...*QueryOptions) (OsvSchemaSlice[EcosystemSpecific, DatabaseSpecific], error) {
+	var err error
+	slice := x.Filter(func(osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) bool {
+		if err != nil {
+			return false
+		}
+		// 是否包含已撤回的漏洞由 QueryOptions 决定
+		affected, e := osvSchema.isPackageVersionAffected(ecosystem, name, version)
+		if e != nil {
+			err = fmt.Errorf("%s: %w", osvSchema.ID, e)
+		}
+		return affected
+	}, options...)
+	if err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
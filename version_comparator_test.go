@@ -0,0 +1,59 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVersionComparators(t *testing.T) {
+	// 每一组中的版本都是从小到大排列的
+	cases := map[string]struct {
+		comparator VersionComparator
+		versions   []string
+	}{
+		"semver":   {SemverComparator, []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "v1.2", "1.10.0"}},
+		"pep440":   {PEP440Comparator, []string{"1.0.dev0", "1.0a1", "1.0b2.post3", "1.0rc1", "1.0", "1.0+local.1", "1.0.post1", "1.1", "2!0.1"}},
+		"maven":    {MavenComparator, []string{"1-alpha-1", "1.0-beta", "1.0-SNAPSHOT", "1.0", "1.0-sp", "1.0.1", "1.1"}},
+		"rubygems": {RubyGemsComparator, []string{"1.0.a", "1.0.b1", "1.0.rc1", "1.0", "1.0.1", "1.10"}},
+		"debian":   {DebianComparator, []string{"1.0~rc1-1", "1.0-1", "1.0-1ubuntu1", "1.0-2", "1.0.1-1", "1:0.9-1"}},
+		"generic":  {GenericComparator, []string{"1.0-alpha", "1.0-rc1", "1.0", "1.0a", "1.0-r1", "1.0.1", "1.10"}},
+	}
+	for name, c := range cases {
+		for i := 0; i+1 < len(c.versions); i++ {
+			n, err := c.comparator.Compare(c.versions[i], c.versions[i+1])
+			assert.Nil(t, err, name)
+			assert.Less(t, n, 0, "%s: %s < %s", name, c.versions[i], c.versions[i+1])
+			n, err = c.comparator.Compare(c.versions[i+1], c.versions[i])
+			assert.Nil(t, err, name)
+			assert.Greater(t, n, 0, "%s: %s > %s", name, c.versions[i+1], c.versions[i])
+		}
+	}
+
+	for _, equal := range [][]string{{"1.0", "1.0.0"}, {"1.0a", "1.0-alpha"}} {
+		n, err := PEP440Comparator.Compare(equal[0], equal[1])
+		assert.Nil(t, err)
+		assert.Equal(t, 0, n)
+	}
+	for _, equal := range [][]string{{"1", "1.0.0"}, {"1.0-ga", "1.0-final"}, {"1.0cr1", "1.0rc1"}} {
+		n, err := MavenComparator.Compare(equal[0], equal[1])
+		assert.Nil(t, err)
+		assert.Equal(t, 0, n, equal)
+	}
+
+	_, err := SemverComparator.Compare("1.0.0", "not-a-version")
+	assert.NotNil(t, err)
+}
+
+func TestAffected_IsVersionAffected(t *testing.T) {
+	r, err := UnmarshalFromJsonFile[any, any]("test_data/GHSA-vxv8-r8q2-63xw.json")
+	assert.Nil(t, err)
+	for version, expected := range map[string]bool{"2.7.1": true, "2.7.2": false, "2.8.0rc1": false, "2.8.0": true, "2.9.1": false, "2.10.0": false} {
+		affected, err := r.IsPackageVersionAffected(EcosystemPyPI, "tensorflow", version)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, affected, version)
+	}
+
+	slice, err := OsvSchemaSlice[any, any]{r}.FilterByPackageVersion(EcosystemPyPI, "tensorflow-cpu", "2.9.0")
+	assert.Nil(t, err)
+	assert.Len(t, slice, 1)
+}
@@ -0,0 +1,167 @@
+package osv_schema
+
+import (
+	"fmt"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// CompressVersions 根据包的所有已发布的版本，把受影响的版本的列表压缩为最少的事件：连续的受影响的版本合并为一个区间，
+// 区间的上界是下一个已发布的版本（ fixed ），一直到最新的版本都受影响的时候使用 last_affected ，不会假设未来的版本也受影响，
+// 从最早的版本开始受影响的时候使用 introduced: 0 。不在已发布的版本中的受影响的版本也会参与排序
+func CompressVersions(comparator VersionComparator, versions, published []string) (Events, error) {
+	affected := make(map[string]bool)
+	seen := make(map[string]bool)
+	all := make([]string, 0, len(versions)+len(published))
+	for _, version := range versions {
+		affected[version] = true
+	}
+	for _, version := range append(append([]string{}, versions...), published...) {
+		if !seen[version] {
+			seen[version] = true
+			all = append(all, version)
+		}
+	}
+	if err := SortVersions(comparator, all); err != nil {
+		return nil, err
+	}
+
+	// 比较器认为相等的版本只保留一个，比如PyPI的 1.0 和 1.0.0 ，其中任何一个受影响就认为这个版本受影响，受影响的时候保留受影响的写法
+	runs := make([]string, 0, len(all))
+	for _, version := range all {
+		if len(runs) != 0 {
+			last := runs[len(runs)-1]
+			n, err := comparator.Compare(last, version)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				if affected[version] && !affected[last] {
+					runs[len(runs)-1] = version
+				}
+				continue
+			}
+		}
+		runs = append(runs, version)
+	}
+
+	events := make(Events, 0)
+	inRun := false
+	for i, version := range runs {
+		if affected[version] && !inRun {
+			if i == 0 {
+				events = append(events, &Event{Introduced: "0"})
+			} else {
+				events = append(events, &Event{Introduced: version})
+			}
+			inRun = true
+		} else if !affected[version] && inRun {
+			events = append(events, &Event{Fixed: version})
+			inRun = false
+		}
+	}
+	if inRun {
+		events = append(events, &Event{LastAffected: runs[len(runs)-1]})
+	}
+	return events, nil
+}
+
+// VersionsToRange 把 Versions 压缩为一个ECOSYSTEM类型的范围，@see CompressVersions
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) VersionsToRange(published []string) (*Range[DatabaseSpecific], error) {
+	if x.Package == nil {
+		return nil, fmt.Errorf("affected has no package")
+	}
+	if len(x.Versions) == 0 {
+		return nil, fmt.Errorf("%s has no versions", x.Package.Name)
+	}
+	comparator, err := GetVersionComparator(x.Package.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	events, err := CompressVersions(comparator, x.Versions, published)
+	if err != nil {
+		return nil, err
+	}
+	return &Range[DatabaseSpecific]{Type: RangeTypeEcosystem, Events: events}, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// VersionInconsistencyType Versions 和 Ranges 不一致的类型
+type VersionInconsistencyType string
+
+const (
+
+	// VersionInconsistencyNotInRanges Versions 中列出的版本不在任何一个范围中
+	VersionInconsistencyNotInRanges VersionInconsistencyType = "NOT_IN_RANGES"
+
+	// VersionInconsistencyNotInVersions 已发布的版本在范围中，但是没有在 Versions 中列出
+	VersionInconsistencyNotInVersions VersionInconsistencyType = "NOT_IN_VERSIONS"
+)
+
+// VersionInconsistency Versions 和 Ranges 不一致的一个版本
+type VersionInconsistency struct {
+	Type    VersionInconsistencyType
+	Version string
+}
+
+func (x *VersionInconsistency) String() string {
+	switch x.Type {
+	case VersionInconsistencyNotInRanges:
+		return fmt.Sprintf("version %s is listed but not in any range", x.Version)
+	default:
+		return fmt.Sprintf("version %s is in a range but not listed", x.Version)
+	}
+}
+
+// CheckVersionsConsistency 检查 Versions 和SEMVER、ECOSYSTEM类型的范围是否一致：
+// 列出的版本不在任何一个范围中的，以及提供了已发布的版本时在范围中但是没有列出的版本。
+// 没有SEMVER、ECOSYSTEM类型的范围或者没有列出版本的时候不做检查，返回的不一致的版本按照版本从小到大排列
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) CheckVersionsConsistency(published []string) ([]*VersionInconsistency, error) {
+	set, err := x.affectedVersionSet()
+	if err != nil {
+		return nil, err
+	}
+	if !set.hasRanges() || len(x.Versions) == 0 {
+		return nil, nil
+	}
+
+	inconsistencies := make([]*VersionInconsistency, 0)
+	versions := append([]string(nil), set.versions...)
+	for _, version := range published {
+		listed, err := set.isListed(version)
+		if err != nil {
+			return nil, err
+		}
+		if !listed {
+			versions = append(versions, version)
+		}
+	}
+	if err := SortVersions(set.comparator, versions); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, version := range versions {
+		if seen[version] {
+			continue
+		}
+		seen[version] = true
+		inRanges, err := set.inRanges(version)
+		if err != nil {
+			return nil, err
+		}
+		listed, err := set.isListed(version)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case listed && !inRanges:
+			inconsistencies = append(inconsistencies, &VersionInconsistency{Type: VersionInconsistencyNotInRanges, Version: version})
+		case !listed && inRanges:
+			inconsistencies = append(inconsistencies, &VersionInconsistency{Type: VersionInconsistencyNotInVersions, Version: version})
+		}
+	}
+	return inconsistencies, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,50 @@
+package osv_schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressVersions(t *testing.T) {
+	published := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "2.0.0", "2.1.0"}
+	events, err := CompressVersions(SemverComparator, []string{"1.1.0", "1.0.0", "2.1.0", "2.0.0"}, published)
+	assert.Nil(t, err)
+	assert.Equal(t, Events{{Introduced: "0"}, {Fixed: "1.2.0"}, {Introduced: "2.0.0"}, {LastAffected: "2.1.0"}}, events)
+
+	// 比较器认为相等的版本是同一个版本，比如PyPI的 1.0 和 1.0.0
+	pypiComparator, err := GetVersionComparator(EcosystemPyPI)
+	assert.Nil(t, err)
+	events, err = CompressVersions(pypiComparator, []string{"1.0"}, []string{"1.0.0", "1.1"})
+	assert.Nil(t, err)
+	assert.Equal(t, Events{{Introduced: "0"}, {Fixed: "1.1"}}, events)
+	events, err = CompressVersions(pypiComparator, []string{"1.1.0"}, []string{"1.0", "1.1", "1.2"})
+	assert.Nil(t, err)
+	assert.Equal(t, Events{{Introduced: "1.1.0"}, {Fixed: "1.2"}}, events)
+
+	affected := &Affected[any, any]{
+		Package:  &Package{Ecosystem: EcosystemNpm, Name: "foo"},
+		Versions: []string{"1.2.0", "1.3.0"},
+	}
+	r, err := affected.VersionsToRange(published)
+	assert.Nil(t, err)
+	assert.Equal(t, Events{{Introduced: "1.2.0"}, {Fixed: "2.0.0"}}, r.Events)
+
+	affected.Ranges = []*Range[any]{{Type: RangeTypeSemver, Events: Events{{Introduced: "1.1.0"}, {Fixed: "1.3.0"}}}}
+	inconsistencies, err := affected.CheckVersionsConsistency(published)
+	assert.Nil(t, err)
+	assert.Equal(t, []*VersionInconsistency{
+		{Type: VersionInconsistencyNotInVersions, Version: "1.1.0"},
+		{Type: VersionInconsistencyNotInRanges, Version: "1.3.0"},
+	}, inconsistencies)
+
+	// 列出的版本和范围中的版本按照比较器判断是否相等
+	pypi := &Affected[any, any]{
+		Package:  &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges:   []*Range[any]{{Type: RangeTypeEcosystem, Events: Events{{Introduced: "1.0.0"}, {Fixed: "1.2"}}}},
+		Versions: []string{"1.0", "1.1.0"},
+	}
+	inconsistencies, err = pypi.CheckVersionsConsistency([]string{"1.0.0", "1.1", "1.2"})
+	assert.Nil(t, err)
+	assert.Empty(t, inconsistencies)
+}
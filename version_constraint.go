@@ -0,0 +1,783 @@
+package osv_schema
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// ConstraintSyntax 包管理器的版本约束语法
+type ConstraintSyntax string
+
+const (
+
+	// ConstraintSyntaxNpm node-semver的范围，比如 >=1.2.0 <1.4.5 || ^2
+	// 参考文档： https://github.com/npm/node-semver#ranges
+	ConstraintSyntaxNpm ConstraintSyntax = "npm"
+
+	// ConstraintSyntaxPython PEP 440 的版本说明符，比如 >=1.0,<2.0
+	// 参考文档： https://peps.python.org/pep-0440/#version-specifiers
+	ConstraintSyntaxPython ConstraintSyntax = "python"
+
+	// ConstraintSyntaxMaven Maven的版本范围，比如 [1.0,2.0)
+	// 参考文档： https://maven.apache.org/pom.html#dependency-version-requirement-specification
+	ConstraintSyntaxMaven ConstraintSyntax = "maven"
+
+	// ConstraintSyntaxCargo Cargo的版本要求，比如 >=1.2.0, <1.4.5
+	// 参考文档： https://doc.rust-lang.org/cargo/reference/specifying-dependencies.html
+	ConstraintSyntaxCargo ConstraintSyntax = "cargo"
+
+	// ConstraintSyntaxComposer Composer的版本约束，比如 >=1.2.0 <1.4.5 || ^2.0
+	// 参考文档： https://getcomposer.org/doc/articles/versions.md
+	ConstraintSyntaxComposer ConstraintSyntax = "composer"
+
+	// ConstraintSyntaxRubyGems RubyGems的版本要求，比如 >= 1.2.0, < 1.4.5
+	// 参考文档： https://guides.rubygems.org/patterns/#declaring-dependencies
+	ConstraintSyntaxRubyGems ConstraintSyntax = "rubygems"
+)
+
+// ErrInexactConstraint 版本约束和OSV的事件之间无法精确地互相转换，比如事件中有多个区间但是约束语法不支持“或”，
+// 或者约束中有不包含下界的区间（ > 、 != ）
+var ErrInexactConstraint = errors.New("constraint can not be represented exactly")
+
+// GetConstraintSyntax 获取包管理器使用的版本约束语法，不支持的包管理器返回 ErrUnsupportedEcosystem
+func GetConstraintSyntax(ecosystem Ecosystem) (ConstraintSyntax, error) {
+	switch ecosystem.Base() {
+	case EcosystemNpm:
+		return ConstraintSyntaxNpm, nil
+	case EcosystemPyPI:
+		return ConstraintSyntaxPython, nil
+	case EcosystemMaven:
+		return ConstraintSyntaxMaven, nil
+	case EcosystemCratesIo:
+		return ConstraintSyntaxCargo, nil
+	case EcosystemPackagist:
+		return ConstraintSyntaxComposer, nil
+	case EcosystemRubyGems:
+		return ConstraintSyntaxRubyGems, nil
+	default:
+		return "", fmt.Errorf("%w: %s has no constraint syntax", ErrUnsupportedEcosystem, ecosystem)
+	}
+}
+
+// 约束语法对应的版本比较器
+func (x ConstraintSyntax) comparator() (VersionComparator, error) {
+	switch x {
+	case ConstraintSyntaxNpm, ConstraintSyntaxCargo:
+		return SemverComparator, nil
+	case ConstraintSyntaxPython:
+		return PEP440Comparator, nil
+	case ConstraintSyntaxMaven:
+		return MavenComparator, nil
+	case ConstraintSyntaxComposer:
+		return GenericComparator, nil
+	case ConstraintSyntaxRubyGems:
+		return RubyGemsComparator, nil
+	default:
+		return nil, fmt.Errorf("unknown constraint syntax %q", x)
+	}
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// FormatConstraint 把SEMVER或者ECOSYSTEM类型的范围的事件转换为包管理器的版本约束，事件互相矛盾的时候返回 *RangeConflictError ，
+// 约束语法无法精确表示的时候（比如Python、Cargo、RubyGems的约束不支持“或”，无法表示多个区间）返回 ErrInexactConstraint
+func FormatConstraint(syntax ConstraintSyntax, events Events) (string, error) {
+	comparator, err := syntax.comparator()
+	if err != nil {
+		return "", err
+	}
+	intervals, conflicts, err := eventsToIntervals(comparator, dedupeEvents(events))
+	if err != nil {
+		return "", err
+	}
+	if len(conflicts) != 0 {
+		return "", &RangeConflictError{Conflicts: conflicts}
+	}
+	merged, err := mergeIntervals(comparator, intervals)
+	if err != nil {
+		return "", err
+	}
+	return formatConstraint(syntax, merged)
+}
+
+// Constraint 把所有SEMVER和ECOSYSTEM类型的范围合并起来转换为包所在的包管理器的版本约束，@see FormatConstraint
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) Constraint() (string, error) {
+	if x.Package == nil {
+		return "", fmt.Errorf("affected has no package")
+	}
+	syntax, err := GetConstraintSyntax(x.Package.Ecosystem)
+	if err != nil {
+		return "", err
+	}
+	comparator, err := syntax.comparator()
+	if err != nil {
+		return "", err
+	}
+	normalized, err := x.Normalize()
+	if err != nil {
+		return "", err
+	}
+	intervals := make(VersionIntervals, 0)
+	for _, r := range normalized.Ranges {
+		if r.Type == RangeTypeGit {
+			continue
+		}
+		rangeIntervals, _, err := eventsToIntervals(comparator, r.Events)
+		if err != nil {
+			return "", err
+		}
+		intervals = append(intervals, rangeIntervals...)
+	}
+	if len(intervals) == 0 {
+		return "", fmt.Errorf("%s has no version range", x.Package.Name)
+	}
+	merged, err := mergeIntervals(comparator, intervals)
+	if err != nil {
+		return "", err
+	}
+	return formatConstraint(syntax, merged)
+}
+
+func formatConstraint(syntax ConstraintSyntax, intervals VersionIntervals) (string, error) {
+	if len(intervals) == 0 {
+		return "", fmt.Errorf("events affect no version")
+	}
+	if len(intervals) > 1 {
+		switch syntax {
+		case ConstraintSyntaxPython, ConstraintSyntaxCargo, ConstraintSyntaxRubyGems:
+			return "", fmt.Errorf("%w: %s constraints can not express %d disjoint intervals", ErrInexactConstraint, syntax, len(intervals))
+		}
+	}
+
+	parts := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		switch syntax {
+		case ConstraintSyntaxMaven:
+			parts = append(parts, formatMavenInterval(interval))
+		case ConstraintSyntaxNpm, ConstraintSyntaxComposer:
+			parts = append(parts, formatComparators(interval, "", "", " ", "*"))
+		case ConstraintSyntaxPython:
+			parts = append(parts, formatComparators(interval, "==", "", ",", ""))
+		case ConstraintSyntaxCargo:
+			parts = append(parts, formatComparators(interval, "=", "", ", ", "*"))
+		case ConstraintSyntaxRubyGems:
+			parts = append(parts, formatComparators(interval, "=", " ", ", ", ">= 0"))
+		}
+	}
+	if syntax == ConstraintSyntaxMaven {
+		return strings.Join(parts, ","), nil
+	}
+	return strings.Join(parts, " || "), nil
+}
+
+// 把区间格式化为比较运算符的形式，比如 >=1.0 <2.0
+func formatComparators(interval *VersionInterval, exactOperator, operatorSeparator, separator, all string) string {
+	if interval.Lower != "" && interval.Lower == interval.Upper && interval.LowerInclusive && interval.UpperInclusive {
+		return exactOperator + operatorSeparator + interval.Lower
+	}
+	comparators := make([]string, 0, 2)
+	if interval.Lower != "" {
+		operator := ">"
+		if interval.LowerInclusive {
+			operator = ">="
+		}
+		comparators = append(comparators, operator+operatorSeparator+interval.Lower)
+	}
+	if interval.Upper != "" {
+		operator := "<"
+		if interval.UpperInclusive {
+			operator = "<="
+		}
+		comparators = append(comparators, operator+operatorSeparator+interval.Upper)
+	}
+	if len(comparators) == 0 {
+		return all
+	}
+	return strings.Join(comparators, separator)
+}
+
+func formatMavenInterval(interval *VersionInterval) string {
+	if interval.Lower != "" && interval.Lower == interval.Upper && interval.LowerInclusive && interval.UpperInclusive {
+		return "[" + interval.Lower + "]"
+	}
+	lower, upper := "(", ")"
+	if interval.Lower != "" && interval.LowerInclusive {
+		lower = "["
+	}
+	if interval.Upper != "" && interval.UpperInclusive {
+		upper = "]"
+	}
+	return lower + interval.Lower + "," + interval.Upper + upper
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// ParseConstraint 把包管理器的版本约束转换为OSV的事件，约束中有不包含下界的区间（比如 >1.0 、 !=1.0 ）的时候返回 ErrInexactConstraint 。
+// node-semver和PEP 440中关于预发布版本的特殊匹配规则无法用事件表示，这里不做处理，
+// ^ 、 ~ 等运算符的上界会带上最小的预发布后缀（比如 ^1.2.3 的上界是 2.0.0-0 ），以排除下一个版本的预发布版本
+func ParseConstraint(syntax ConstraintSyntax, constraint string) (Events, error) {
+	comparator, err := syntax.comparator()
+	if err != nil {
+		return nil, err
+	}
+	var intervals VersionIntervals
+	switch syntax {
+	case ConstraintSyntaxNpm:
+		intervals, err = parseSemverStyleConstraint(npmConstraintDialect, comparator, constraint)
+	case ConstraintSyntaxCargo:
+		intervals, err = parseSemverStyleConstraint(cargoConstraintDialect, comparator, constraint)
+	case ConstraintSyntaxComposer:
+		intervals, err = parseSemverStyleConstraint(composerConstraintDialect, comparator, constraint)
+	case ConstraintSyntaxPython:
+		intervals, err = parseComparatorList(comparator, constraint, pythonSpecifierIntervals)
+	case ConstraintSyntaxRubyGems:
+		intervals, err = parseComparatorList(comparator, constraint, rubyGemsRequirementIntervals)
+	case ConstraintSyntaxMaven:
+		intervals, err = parseMavenConstraint(comparator, constraint)
+	}
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergeIntervals(comparator, intervals)
+	if err != nil {
+		return nil, err
+	}
+	if len(merged) == 0 {
+		return nil, fmt.Errorf("constraint %q matches no version", constraint)
+	}
+	events, err := merged.ToEvents()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %s", ErrInexactConstraint, constraint, err.Error())
+	}
+	return events, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 所有版本
+func allVersions() VersionIntervals {
+	return VersionIntervals{{}}
+}
+
+// 单个版本
+func exactVersion(version string) VersionIntervals {
+	return VersionIntervals{{Lower: version, LowerInclusive: true, Upper: version, UpperInclusive: true}}
+}
+
+// 除了某个版本之外的所有版本
+func exceptVersion(version string) VersionIntervals {
+	return VersionIntervals{{Upper: version}, {Lower: version}}
+}
+
+// 按运算符生成区间
+func comparatorIntervals(operator, version string) (VersionIntervals, bool) {
+	switch operator {
+	case "<":
+		return VersionIntervals{{Upper: version}}, true
+	case "<=":
+		return VersionIntervals{{Upper: version, UpperInclusive: true}}, true
+	case ">":
+		return VersionIntervals{{Lower: version}}, true
+	case ">=":
+		return VersionIntervals{{Lower: version, LowerInclusive: true}}, true
+	default:
+		return nil, false
+	}
+}
+
+// 检查版本号是否合法
+func validateConstraintVersion(comparator VersionComparator, version string) error {
+	_, err := comparator.Compare(version, version)
+	return err
+}
+
+// 把字符串开头的运算符拆出来，operators 需要按照长度从长到短排列
+func splitOperator(s string, operators []string) (string, string) {
+	for _, operator := range operators {
+		if strings.HasPrefix(s, operator) {
+			return operator, strings.TrimSpace(s[len(operator):])
+		}
+	}
+	return "", s
+}
+
+// 以逗号分隔、所有比较都要满足的约束，比如Python和RubyGems的约束
+func parseComparatorList(comparator VersionComparator, constraint string, parseFunc func(comparator VersionComparator, specifier string) (VersionIntervals, error)) (VersionIntervals, error) {
+	result := allVersions()
+	for _, specifier := range strings.Split(constraint, ",") {
+		specifier = strings.TrimSpace(specifier)
+		if specifier == "" {
+			if strings.TrimSpace(constraint) == "" {
+				break
+			}
+			return nil, fmt.Errorf("invalid constraint %q: empty specifier", constraint)
+		}
+		intervals, err := parseFunc(comparator, specifier)
+		if err != nil {
+			return nil, err
+		}
+		if result, err = intersectIntervals(comparator, result, intervals); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// npm、Cargo、Composer的约束都是从node-semver演变来的，区别在于一些细节
+type semverConstraintDialect struct {
+	name string
+
+	// 支持的运算符，按照长度从长到短排列
+	operators []string
+
+	// “或”的分隔符，为空表示不支持“或”
+	orSeparator *regexp.Regexp
+
+	// “与”的分隔符
+	andSeparator *regexp.Regexp
+
+	// 是否支持 1.0 - 2.0 这样的连字符范围
+	hyphenRange bool
+
+	// 没有运算符的时候是否等价于 ^ ，Cargo是这样的
+	bareIsCaret bool
+
+	// 省略的部分是否等价于通配符，npm和Cargo中 1.2 等价于 1.2.x ，Composer中 1.2 等价于 1.2.0
+	partialIsWildcard bool
+
+	// ~ 是否锁定倒数第二个部分，Composer中 ~1.2 等价于 >=1.2 <2.0 ，npm中等价于 >=1.2.0 <1.3.0-0
+	tildeBumpsSecondToLast bool
+
+	// 上界的后缀，用于排除下一个版本的预发布版本
+	upperSuffix string
+
+	// 版本号最多有几个数字部分
+	maxParts int
+
+	// 是否支持 @dev 这样的稳定性标记，稳定性标记不影响版本的范围
+	stabilityFlags bool
+}
+
+var npmConstraintDialect = &semverConstraintDialect{
+	name:              "npm",
+	operators:         []string{">=", "<=", ">", "<", "=", "~>", "~", "^"},
+	orSeparator:       regexp.MustCompile(`\|\|`),
+	andSeparator:      regexp.MustCompile(`\s+`),
+	hyphenRange:       true,
+	partialIsWildcard: true,
+	upperSuffix:       "-0",
+	maxParts:          3,
+}
+
+var cargoConstraintDialect = &semverConstraintDialect{
+	name:              "cargo",
+	operators:         []string{">=", "<=", ">", "<", "=", "~", "^"},
+	andSeparator:      regexp.MustCompile(`\s*,\s*`),
+	bareIsCaret:       true,
+	partialIsWildcard: true,
+	upperSuffix:       "-0",
+	maxParts:          3,
+}
+
+var composerConstraintDialect = &semverConstraintDialect{
+	name:                   "composer",
+	operators:              []string{">=", "<=", "==", "!=", "<>", ">", "<", "=", "~", "^"},
+	orSeparator:            regexp.MustCompile(`\|\|?`),
+	andSeparator:           regexp.MustCompile(`\s*,\s*|\s+`),
+	hyphenRange:            true,
+	tildeBumpsSecondToLast: true,
+	upperSuffix:            "-dev",
+	maxParts:               4,
+	stabilityFlags:         true,
+}
+
+// 运算符和版本号之间的空白
+var operatorSpaceRegexp = regexp.MustCompile(`(>=|<=|==|!=|<>|~>|[<>=~^])\s+`)
+
+var hyphenRangeRegexp = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+
+func parseSemverStyleConstraint(dialect *semverConstraintDialect, comparator VersionComparator, constraint string) (VersionIntervals, error) {
+	sets := []string{constraint}
+	if dialect.orSeparator != nil {
+		sets = dialect.orSeparator.Split(constraint, -1)
+	}
+	union := make(VersionIntervals, 0)
+	for _, set := range sets {
+		set = strings.TrimSpace(set)
+		if set == "" {
+			if len(sets) > 1 {
+				return nil, fmt.Errorf("invalid %s constraint %q: empty alternative", dialect.name, constraint)
+			}
+			return allVersions(), nil
+		}
+
+		var tokens [][2]string
+		if match := hyphenRangeRegexp.FindStringSubmatch(set); dialect.hyphenRange && match != nil {
+			upper, err := dialect.hyphenUpper(match[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s constraint %q: %w", dialect.name, constraint, err)
+			}
+			tokens = [][2]string{{">=", match[1]}, upper}
+		} else {
+			for _, token := range dialect.andSeparator.Split(operatorSpaceRegexp.ReplaceAllString(set, "$1"), -1) {
+				if token == "" {
+					continue
+				}
+				operator, version := splitOperator(token, dialect.operators)
+				tokens = append(tokens, [2]string{operator, version})
+			}
+		}
+
+		intervals := allVersions()
+		for _, token := range tokens {
+			tokenIntervals, err := dialect.intervals(comparator, token[0], token[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s constraint %q: %w", dialect.name, constraint, err)
+			}
+			if intervals, err = intersectIntervals(comparator, intervals, tokenIntervals); err != nil {
+				return nil, err
+			}
+		}
+		union = append(union, intervals...)
+	}
+	return union, nil
+}
+
+// 连字符范围的上界：完整的版本号是包含的；省略了一部分的版本号在npm中按照通配符处理，
+// Composer中也表示这一部分的所有版本，比如 1.0 - 2.0 等价于 >=1.0 <2.1
+func (d *semverConstraintDialect) hyphenUpper(version string) ([2]string, error) {
+	if d.partialIsWildcard {
+		return [2]string{"<=", version}, nil
+	}
+	v, err := d.parseVersion(version)
+	if err != nil {
+		return [2]string{}, err
+	}
+	if v.wildcard || v.suffix != "" || len(v.parts) >= 3 {
+		return [2]string{"<=", version}, nil
+	}
+	parts := make([]string, 0, len(v.parts))
+	for i, n := range v.parts {
+		if i == len(v.parts)-1 {
+			n++
+		}
+		parts = append(parts, strconv.FormatUint(n, 10))
+	}
+	return [2]string{"<", strings.Join(parts, ".")}, nil
+}
+
+// 可能省略了一部分或者带有通配符的版本号，比如 1.2 、 1.2.x 、 *
+type partialVersion struct {
+
+	// 给出的数字部分
+	parts []uint64
+
+	// 是否带有通配符
+	wildcard bool
+
+	// 预发布版本和编译信息，比如 -beta.1+build
+	suffix string
+}
+
+func (d *semverConstraintDialect) parseVersion(version string) (*partialVersion, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if d.stabilityFlags {
+		if index := strings.Index(s, "@"); index >= 0 {
+			s = s[:index]
+		}
+	}
+	result := &partialVersion{}
+	if index := strings.IndexAny(s, "-+"); index >= 0 {
+		s, result.suffix = s[:index], s[index:]
+	}
+	if s == "" {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	for _, part := range strings.Split(s, ".") {
+		if part == "*" || part == "x" || part == "X" {
+			result.wildcard = true
+			continue
+		}
+		if result.wildcard {
+			return nil, fmt.Errorf("invalid version %q: number after wildcard", version)
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", version)
+		}
+		result.parts = append(result.parts, n)
+	}
+	if len(result.parts) > d.maxParts {
+		return nil, fmt.Errorf("invalid version %q: too many parts", version)
+	}
+	if d.partialIsWildcard && len(result.parts) < 3 {
+		result.wildcard = true
+	}
+	if result.wildcard && result.suffix != "" {
+		return nil, fmt.Errorf("invalid version %q: prerelease on partial version", version)
+	}
+	return result, nil
+}
+
+// 补全为 major.minor.patch 的形式
+func (x *partialVersion) lower() string {
+	parts := make([]string, 0, 3)
+	for _, n := range x.parts {
+		parts = append(parts, strconv.FormatUint(n, 10))
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts, ".") + x.suffix
+}
+
+// 把第index个部分加1，后面的部分都置为0
+func (x *partialVersion) bump(index int, suffix string) string {
+	bumped := &partialVersion{parts: append([]uint64(nil), x.parts[:index+1]...), suffix: suffix}
+	bumped.parts[index]++
+	return bumped.lower()
+}
+
+func (d *semverConstraintDialect) intervals(comparator VersionComparator, operator, version string) (VersionIntervals, error) {
+	v, err := d.parseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	if len(v.parts) == 0 {
+		// * 匹配所有的版本， <* 和 >* 不匹配任何版本
+		if operator == "<" || operator == ">" || operator == "!=" || operator == "<>" {
+			return VersionIntervals{}, nil
+		}
+		return allVersions(), nil
+	}
+	lower := v.lower()
+	if err := validateConstraintVersion(comparator, lower); err != nil {
+		return nil, err
+	}
+	last := len(v.parts) - 1
+	if operator == "" && d.bareIsCaret {
+		operator = "^"
+	}
+
+	switch operator {
+	case "", "=", "==":
+		if v.wildcard {
+			return VersionIntervals{{Lower: lower, LowerInclusive: true, Upper: v.bump(last, d.upperSuffix)}}, nil
+		}
+		return exactVersion(lower), nil
+	case "!=", "<>":
+		if v.wildcard {
+			return VersionIntervals{{Upper: lower}, {Lower: v.bump(last, d.upperSuffix), LowerInclusive: true}}, nil
+		}
+		return exceptVersion(lower), nil
+	case ">":
+		if v.wildcard {
+			return VersionIntervals{{Lower: v.bump(last, ""), LowerInclusive: true}}, nil
+		}
+	case "<":
+		if v.wildcard {
+			return VersionIntervals{{Upper: (&partialVersion{parts: v.parts, suffix: d.upperSuffix}).lower()}}, nil
+		}
+	case "<=":
+		if v.wildcard {
+			return VersionIntervals{{Upper: v.bump(last, d.upperSuffix)}}, nil
+		}
+	case "~", "~>":
+		index := 0
+		if d.tildeBumpsSecondToLast {
+			if last > 0 {
+				index = last - 1
+			}
+		} else if last > 0 {
+			index = 1
+		}
+		return VersionIntervals{{Lower: lower, LowerInclusive: true, Upper: v.bump(index, d.upperSuffix)}}, nil
+	case "^":
+		// 锁定第一个不为0的部分，都是0的话锁定给出的最后一个部分
+		index := last
+		for i, n := range v.parts {
+			if n != 0 {
+				index = i
+				break
+			}
+		}
+		return VersionIntervals{{Lower: lower, LowerInclusive: true, Upper: v.bump(index, d.upperSuffix)}}, nil
+	}
+	if intervals, ok := comparatorIntervals(operator, lower); ok {
+		return intervals, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", operator)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+var pythonSpecifierRegexp = regexp.MustCompile(`^(~=|===|==|!=|<=|>=|<|>)\s*(\S+)$`)
+
+func pythonSpecifierIntervals(comparator VersionComparator, specifier string) (VersionIntervals, error) {
+	match := pythonSpecifierRegexp.FindStringSubmatch(specifier)
+	if match == nil {
+		return nil, fmt.Errorf("invalid python version specifier %q", specifier)
+	}
+	operator, version := match[1], match[2]
+
+	// 前缀匹配，比如 ==1.4.* 等价于 >=1.4.dev0, <1.5.dev0
+	if prefix := strings.TrimSuffix(version, ".*"); prefix != version {
+		if operator != "==" && operator != "!=" {
+			return nil, fmt.Errorf("invalid python version specifier %q: prefix match is only allowed with == and !=", specifier)
+		}
+		v, err := ParsePEP440(prefix)
+		if err != nil {
+			return nil, err
+		}
+		lower, upper := prefix+".dev0", bumpPEP440Release(v, len(v.Release))
+		if operator == "==" {
+			return VersionIntervals{{Lower: lower, LowerInclusive: true, Upper: upper}}, nil
+		}
+		return VersionIntervals{{Upper: lower}, {Lower: upper, LowerInclusive: true}}, nil
+	}
+
+	v, err := ParsePEP440(version)
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case "==", "===":
+		return exactVersion(version), nil
+	case "!=":
+		return exceptVersion(version), nil
+	case "~=":
+		// ~=1.4.5 等价于 >=1.4.5, ==1.4.*
+		if len(v.Release) < 2 {
+			return nil, fmt.Errorf("invalid python version specifier %q: ~= needs at least two release segments", specifier)
+		}
+		return VersionIntervals{{Lower: version, LowerInclusive: true, Upper: bumpPEP440Release(v, len(v.Release)-1)}}, nil
+	}
+	intervals, _ := comparatorIntervals(operator, version)
+	return intervals, nil
+}
+
+// 保留前n个发布版本号并把最后一个加1，返回其中最小的开发版本，比如 1.4 的 n=2 返回 1.5.dev0
+func bumpPEP440Release(v *PEP440Version, n int) string {
+	parts := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		number := v.Release[i]
+		if i == n-1 {
+			number = new(big.Int).Add(number, big.NewInt(1))
+		}
+		parts = append(parts, number.String())
+	}
+	s := strings.Join(parts, ".") + ".dev0"
+	if v.Epoch.Sign() != 0 {
+		s = v.Epoch.String() + "!" + s
+	}
+	return s
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+var rubyGemsOperators = []string{">=", "<=", "!=", "~>", ">", "<", "="}
+
+func rubyGemsRequirementIntervals(comparator VersionComparator, requirement string) (VersionIntervals, error) {
+	operator, version := splitOperator(requirement, rubyGemsOperators)
+	if err := validateConstraintVersion(comparator, version); err != nil {
+		return nil, err
+	}
+	switch operator {
+	case "", "=":
+		return exactVersion(version), nil
+	case "!=":
+		return exceptVersion(version), nil
+	case "~>":
+		// 和 Gem::Version#bump 一致：去掉预发布部分和最后一个部分，再把最后一个部分加1， ~> 2.2.0 等价于 >= 2.2.0, < 2.3.a
+		segments := strings.Split(version, ".")
+		for i, segment := range segments {
+			if _, err := strconv.ParseUint(segment, 10, 64); err != nil {
+				segments = segments[:i]
+				break
+			}
+		}
+		if len(segments) > 1 {
+			segments = segments[:len(segments)-1]
+		}
+		if len(segments) == 0 {
+			return nil, fmt.Errorf("invalid RubyGems requirement %q", requirement)
+		}
+		n, _ := new(big.Int).SetString(segments[len(segments)-1], 10)
+		segments[len(segments)-1] = n.Add(n, big.NewInt(1)).String()
+		return VersionIntervals{{Lower: version, LowerInclusive: true, Upper: strings.Join(segments, ".") + ".a"}}, nil
+	}
+	intervals, _ := comparatorIntervals(operator, version)
+	return intervals, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// Maven的版本范围，多个范围之间用逗号分隔表示“或”，没有括号的版本号是软性要求，这里当做精确的版本处理
+func parseMavenConstraint(comparator VersionComparator, constraint string) (VersionIntervals, error) {
+	s := strings.Join(strings.Fields(constraint), "")
+	if s == "" {
+		return nil, fmt.Errorf("invalid maven version range %q", constraint)
+	}
+	if s[0] != '[' && s[0] != '(' {
+		return exactVersion(s), nil
+	}
+
+	result := make(VersionIntervals, 0)
+	for s != "" {
+		if s[0] != '[' && s[0] != '(' {
+			return nil, fmt.Errorf("invalid maven version range %q", constraint)
+		}
+		end := strings.IndexAny(s, "])")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid maven version range %q: unbalanced brackets", constraint)
+		}
+		opening, body, closing := s[0], s[1:end], s[end]
+		bounds := strings.Split(body, ",")
+		switch len(bounds) {
+		case 1:
+			if opening != '[' || closing != ']' || body == "" {
+				return nil, fmt.Errorf("invalid maven version range %q: single version must be written as [version]", constraint)
+			}
+			result = append(result, exactVersion(body)...)
+		case 2:
+			interval := &VersionInterval{Lower: bounds[0], LowerInclusive: opening == '[', Upper: bounds[1], UpperInclusive: closing == ']'}
+			if interval.Lower == "" {
+				interval.LowerInclusive = false
+			}
+			if interval.Upper == "" {
+				interval.UpperInclusive = false
+			}
+			result = append(result, interval)
+		default:
+			return nil, fmt.Errorf("invalid maven version range %q", constraint)
+		}
+		s = s[end+1:]
+		if strings.HasPrefix(s, ",") {
+			s = s[1:]
+			if s == "" {
+				return nil, fmt.Errorf("invalid maven version range %q: trailing comma", constraint)
+			}
+		}
+	}
+	for _, interval := range result {
+		for _, version := range []string{interval.Lower, interval.Upper} {
+			if version == "" {
+				continue
+			}
+			if err := validateConstraintVersion(comparator, version); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
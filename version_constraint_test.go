@@ -0,0 +1,90 @@
+package osv_schema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConstraint(t *testing.T) {
+	cases := []struct {
+		syntax     ConstraintSyntax
+		constraint string
+		expected   Events
+	}{
+		{ConstraintSyntaxNpm, ">=1.2.0 <1.4.5 || ^2", Events{{Introduced: "1.2.0"}, {Fixed: "1.4.5"}, {Introduced: "2.0.0"}, {Fixed: "3.0.0-0"}}},
+		{ConstraintSyntaxNpm, "~0.2.3 || 1.2.3 - 1.3", Events{{Introduced: "0.2.3"}, {Fixed: "0.3.0-0"}, {Introduced: "1.2.3"}, {Fixed: "1.4.0-0"}}},
+		{ConstraintSyntaxNpm, "<= 1.0.0", Events{{Introduced: "0"}, {LastAffected: "1.0.0"}}},
+		{ConstraintSyntaxPython, ">=1.0,<2.0", Events{{Introduced: "1.0"}, {Fixed: "2.0"}}},
+		{ConstraintSyntaxPython, "~=1.4.5", Events{{Introduced: "1.4.5"}, {Fixed: "1.5.dev0"}}},
+		{ConstraintSyntaxPython, "==1.4.*", Events{{Introduced: "1.4.dev0"}, {Fixed: "1.5.dev0"}}},
+		{ConstraintSyntaxMaven, "[1.0,2.0),[3.0]", Events{{Introduced: "1.0"}, {Fixed: "2.0"}, {Introduced: "3.0"}, {LastAffected: "3.0"}}},
+		{ConstraintSyntaxMaven, "(,1.0]", Events{{Introduced: "0"}, {LastAffected: "1.0"}}},
+		{ConstraintSyntaxCargo, "1.2", Events{{Introduced: "1.2.0"}, {Fixed: "2.0.0-0"}}},
+		{ConstraintSyntaxCargo, ">=0.1.0, <0.3", Events{{Introduced: "0.1.0"}, {Fixed: "0.3.0-0"}}},
+		{ConstraintSyntaxComposer, "~1.2 || ^0.3@dev", Events{{Introduced: "0.3.0"}, {Fixed: "0.4.0-dev"}, {Introduced: "1.2.0"}, {Fixed: "2.0.0-dev"}}},
+		{ConstraintSyntaxComposer, ">=1.0, <1.1 | 1.5.*", Events{{Introduced: "1.0.0"}, {Fixed: "1.1.0"}, {Introduced: "1.5.0"}, {Fixed: "1.6.0-dev"}}},
+		{ConstraintSyntaxComposer, "1.0 - 2.0", Events{{Introduced: "1.0.0"}, {Fixed: "2.1.0"}}},
+		{ConstraintSyntaxComposer, "1.0 - 2", Events{{Introduced: "1.0.0"}, {Fixed: "3.0.0"}}},
+		{ConstraintSyntaxComposer, "1.0 - 2.0.1", Events{{Introduced: "1.0.0"}, {LastAffected: "2.0.1"}}},
+		{ConstraintSyntaxRubyGems, "~> 2.2.0", Events{{Introduced: "2.2.0"}, {Fixed: "2.3.a"}}},
+		{ConstraintSyntaxRubyGems, ">= 1.0, < 1.4.5", Events{{Introduced: "1.0"}, {Fixed: "1.4.5"}}},
+	}
+	for _, c := range cases {
+		events, err := ParseConstraint(c.syntax, c.constraint)
+		assert.Nil(t, err, c.constraint)
+		assert.Equal(t, c.expected, events, c.constraint)
+	}
+
+	for syntax, constraint := range map[ConstraintSyntax]string{ConstraintSyntaxNpm: ">1.0.0", ConstraintSyntaxPython: "!=1.0", ConstraintSyntaxRubyGems: "!= 1.0"} {
+		_, err := ParseConstraint(syntax, constraint)
+		assert.True(t, errors.Is(err, ErrInexactConstraint), constraint)
+	}
+	_, err := ParseConstraint(ConstraintSyntaxMaven, "[1.0,2.0")
+	assert.NotNil(t, err)
+}
+
+func TestFormatConstraint(t *testing.T) {
+	events := Events{{Introduced: "1.2.0"}, {Fixed: "1.4.5"}, {Introduced: "2.0.0"}, {LastAffected: "2.1.0"}}
+	for syntax, expected := range map[ConstraintSyntax]string{
+		ConstraintSyntaxNpm:      ">=1.2.0 <1.4.5 || >=2.0.0 <=2.1.0",
+		ConstraintSyntaxComposer: ">=1.2.0 <1.4.5 || >=2.0.0 <=2.1.0",
+		ConstraintSyntaxMaven:    "[1.2.0,1.4.5),[2.0.0,2.1.0]",
+	} {
+		constraint, err := FormatConstraint(syntax, events)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, constraint)
+
+		parsed, err := ParseConstraint(syntax, constraint)
+		assert.Nil(t, err)
+		assert.Equal(t, events, parsed)
+	}
+	for _, syntax := range []ConstraintSyntax{ConstraintSyntaxPython, ConstraintSyntaxCargo, ConstraintSyntaxRubyGems} {
+		_, err := FormatConstraint(syntax, events)
+		assert.True(t, errors.Is(err, ErrInexactConstraint))
+	}
+
+	constraint, err := FormatConstraint(ConstraintSyntaxRubyGems, Events{{Introduced: "0"}, {Fixed: "1.4.5"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "< 1.4.5", constraint)
+	constraint, err = FormatConstraint(ConstraintSyntaxPython, Events{{Introduced: "1.0"}, {Fixed: "2.0"}})
+	assert.Nil(t, err)
+	assert.Equal(t, ">=1.0,<2.0", constraint)
+
+	r, err := UnmarshalFromJsonFile[any, any]("test_data/GHSA-vxv8-r8q2-63xw.json")
+	assert.Nil(t, err)
+	constraint, err = r.Affected[0].Constraint()
+	assert.Nil(t, err)
+	assert.Equal(t, "<2.7.2", constraint)
+
+	affected := &Affected[any, any]{
+		Package: &Package{Ecosystem: EcosystemPyPI, Name: "foo"},
+		Ranges: []*Range[any]{
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "0"}, {Fixed: "1.0"}}},
+			{Type: RangeTypeEcosystem, Events: Events{{Introduced: "2.0"}, {Fixed: "2.1"}}},
+		},
+	}
+	_, err = affected.Constraint()
+	assert.True(t, errors.Is(err, ErrInexactConstraint))
+}
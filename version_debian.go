@@ -0,0 +1,116 @@
+package osv_schema
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DebianComparator 按照dpkg的规则比较Debian和Ubuntu的包的版本号，版本号的格式为 [epoch:]upstream_version[-debian_revision]
+// 参考文档： https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+var DebianComparator VersionComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	versionA, err := parseDebianVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	versionB, err := parseDebianVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	if n := versionA.epoch.Cmp(versionB.epoch); n != 0 {
+		return n, nil
+	}
+	if n := compareDebianPart(versionA.upstream, versionB.upstream); n != 0 {
+		return n, nil
+	}
+	return compareDebianPart(versionA.revision, versionB.revision), nil
+})
+
+type debianVersion struct {
+	epoch    *big.Int
+	upstream string
+	revision string
+}
+
+func parseDebianVersion(version string) (*debianVersion, error) {
+	version = strings.TrimSpace(version)
+	result := &debianVersion{epoch: big.NewInt(0)}
+	if index := strings.Index(version, ":"); index >= 0 {
+		epoch, ok := new(big.Int).SetString(version[:index], 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid debian version %q: bad epoch", version)
+		}
+		result.epoch = epoch
+		version = version[index+1:]
+	}
+	if index := strings.LastIndex(version, "-"); index >= 0 {
+		result.revision = version[index+1:]
+		version = version[:index]
+	}
+	if version == "" || version[0] < '0' || version[0] > '9' {
+		return nil, fmt.Errorf("invalid debian version %q: upstream version must start with a digit", version)
+	}
+	result.upstream = version
+	return result, nil
+}
+
+// dpkg中字符的排序权重： ~ 比任何字符都小，包括空字符串，字母比非字母小
+func debianCharOrder(s string, i int) int {
+	if i >= len(s) {
+		return 0
+	}
+	c := s[i]
+	switch {
+	case c >= '0' && c <= '9':
+		return 0
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		return int(c)
+	case c == '~':
+		return -1
+	default:
+		return int(c) + 256
+	}
+}
+
+func isDigitAt(s string, i int) bool {
+	return i < len(s) && s[i] >= '0' && s[i] <= '9'
+}
+
+// 和dpkg的 verrevcmp 一致，非数字部分逐个字符比较，数字部分按数值比较
+func compareDebianPart(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		for (i < len(a) && !isDigitAt(a, i)) || (j < len(b) && !isDigitAt(b, j)) {
+			orderA, orderB := debianCharOrder(a, i), debianCharOrder(b, j)
+			if orderA != orderB {
+				return orderA - orderB
+			}
+			i++
+			j++
+		}
+		for i < len(a) && a[i] == '0' {
+			i++
+		}
+		for j < len(b) && b[j] == '0' {
+			j++
+		}
+		firstDiff := 0
+		for isDigitAt(a, i) && isDigitAt(b, j) {
+			if firstDiff == 0 {
+				firstDiff = int(a[i]) - int(b[j])
+			}
+			i++
+			j++
+		}
+		if isDigitAt(a, i) {
+			return 1
+		}
+		if isDigitAt(b, j) {
+			return -1
+		}
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+	return 0
+}
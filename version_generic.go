@@ -0,0 +1,113 @@
+package osv_schema
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// GenericComparator 通用的版本号比较器，给没有专门的比较器的包管理器使用：
+// 版本号被拆分为连续的数字和连续的字母，其它字符只作为分隔符，数字按数值比较，字母按字典序比较，数字比字母大，
+// alpha 、 beta 、 rc 这种常见的预发布标记比缺失的部分小，其它字母比缺失的部分大，比如 1.0-rc1 < 1.0 < 1.0a < 1.0.1
+var GenericComparator VersionComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	tokensA, err := parseGenericVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	tokensB, err := parseGenericVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < len(tokensA) || i < len(tokensB); i++ {
+		var n int
+		switch {
+		case i >= len(tokensA):
+			n = -compareGenericTokenWithMissing(tokensB[i])
+		case i >= len(tokensB):
+			n = compareGenericTokenWithMissing(tokensA[i])
+		default:
+			n = compareGenericTokens(tokensA[i], tokensB[i])
+		}
+		if n != 0 {
+			return n, nil
+		}
+	}
+	return 0, nil
+})
+
+// 常见的预发布标记，越靠前越小
+var genericPrereleaseTokens = []string{"dev", "snapshot", "alpha", "beta", "milestone", "preview", "pre", "rc", "cr"}
+
+type genericToken struct {
+	number *big.Int
+	text   string
+}
+
+func (x *genericToken) prereleaseRank() int {
+	for i, token := range genericPrereleaseTokens {
+		if token == x.text {
+			return i
+		}
+	}
+	return -1
+}
+
+func parseGenericVersion(version string) ([]*genericToken, error) {
+	version = strings.ToLower(strings.TrimSpace(version))
+	tokens := make([]*genericToken, 0)
+	for i := 0; i < len(version); {
+		start := i
+		switch {
+		case isDigitAt(version, i):
+			for isDigitAt(version, i) {
+				i++
+			}
+			n, _ := new(big.Int).SetString(version[start:i], 10)
+			tokens = append(tokens, &genericToken{number: n})
+		case version[i] >= 'a' && version[i] <= 'z':
+			for i < len(version) && version[i] >= 'a' && version[i] <= 'z' {
+				i++
+			}
+			tokens = append(tokens, &genericToken{text: version[start:i]})
+		default:
+			i++
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid version %q", version)
+	}
+	return tokens, nil
+}
+
+// 和缺失的部分比较，数字的缺失部分当作0
+func compareGenericTokenWithMissing(token *genericToken) int {
+	if token.number != nil {
+		return token.number.Sign()
+	}
+	if token.prereleaseRank() >= 0 {
+		return -1
+	}
+	return 1
+}
+
+func compareGenericTokens(a, b *genericToken) int {
+	switch {
+	case a.number != nil && b.number != nil:
+		return a.number.Cmp(b.number)
+	case a.number != nil:
+		return 1
+	case b.number != nil:
+		return -1
+	}
+	rankA, rankB := a.prereleaseRank(), b.prereleaseRank()
+	switch {
+	case rankA >= 0 && rankB >= 0:
+		return rankA - rankB
+	case rankA >= 0:
+		return -1
+	case rankB >= 0:
+		return 1
+	default:
+		return strings.Compare(a.text, b.text)
+	}
+}
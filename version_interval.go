@@ -0,0 +1,409 @@
+package osv_schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// VersionInterval 一个连续的版本区间，SEMVER和ECOSYSTEM类型的范围可以转换为若干个不重叠的区间
+type VersionInterval struct {
+
+	// 下界，为空表示没有下界
+	Lower string
+
+	// 是否包含下界
+	LowerInclusive bool
+
+	// 上界，为空表示没有上界
+	Upper string
+
+	// 是否包含上界
+	UpperInclusive bool
+}
+
+// VersionIntervals 按照版本从小到大排列的若干个不重叠的版本区间
+type VersionIntervals []*VersionInterval
+
+// Contains 判断版本是否在这个区间中
+func (x *VersionInterval) Contains(comparator VersionComparator, version string) (bool, error) {
+	if x.Lower != "" {
+		n, err := comparator.Compare(version, x.Lower)
+		if err != nil {
+			return false, err
+		}
+		if n < 0 || n == 0 && !x.LowerInclusive {
+			return false, nil
+		}
+	}
+	if x.Upper != "" {
+		n, err := comparator.Compare(version, x.Upper)
+		if err != nil {
+			return false, err
+		}
+		if n > 0 || n == 0 && !x.UpperInclusive {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Contains 判断版本是否在任意一个区间中
+func (x VersionIntervals) Contains(comparator VersionComparator, version string) (bool, error) {
+	for _, interval := range x {
+		ok, err := interval.Contains(comparator, version)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// ToEvents 把区间转换为OSV的事件，事件只能表示包含下界的区间，不包含下界的区间无法精确表示，会返回错误
+func (x VersionIntervals) ToEvents() (Events, error) {
+	events := make(Events, 0, len(x)*2)
+	for _, interval := range x {
+		if interval.Lower == "" {
+			events = append(events, &Event{Introduced: "0"})
+		} else if interval.LowerInclusive {
+			events = append(events, &Event{Introduced: interval.Lower})
+		} else {
+			return nil, fmt.Errorf("interval %s can not be represented by events exactly: the lower bound is exclusive", interval)
+		}
+		switch {
+		case interval.Upper == "":
+		case interval.UpperInclusive:
+			events = append(events, &Event{LastAffected: interval.Upper})
+		default:
+			events = append(events, &Event{Fixed: interval.Upper})
+		}
+	}
+	return events, nil
+}
+
+// Complement 返回区间的补集，区间需要是按照版本从小到大排列的不重叠的区间，比如 mergeIntervals 的结果
+func (x VersionIntervals) Complement() VersionIntervals {
+	result := make(VersionIntervals, 0, len(x)+1)
+	current := &VersionInterval{}
+	for _, interval := range x {
+		if interval.Lower != "" {
+			current.Upper, current.UpperInclusive = interval.Lower, !interval.LowerInclusive
+			result = append(result, current)
+		}
+		if interval.Upper == "" {
+			return result
+		}
+		current = &VersionInterval{Lower: interval.Upper, LowerInclusive: !interval.UpperInclusive}
+	}
+	return append(result, current)
+}
+
+// String 返回node-semver风格的表示，比如 <1.2.0 || >=1.4.5 ，包含所有版本的时候返回 * ，不包含任何版本的时候返回空字符串
+func (x VersionIntervals) String() string {
+	parts := make([]string, 0, len(x))
+	for _, interval := range x {
+		parts = append(parts, formatComparators(interval, "", "", " ", "*"))
+	}
+	return strings.Join(parts, " || ")
+}
+
+// String 返回区间的数学表示，比如 [1.0, 2.0)
+func (x *VersionInterval) String() string {
+	lower, upper := "(-∞", "+∞)"
+	if x.Lower != "" {
+		if x.LowerInclusive {
+			lower = "[" + x.Lower
+		} else {
+			lower = "(" + x.Lower
+		}
+	}
+	if x.Upper != "" {
+		if x.UpperInclusive {
+			upper = x.Upper + "]"
+		} else {
+			upper = x.Upper + ")"
+		}
+	}
+	return lower + ", " + upper
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 比较两个区间的下界，没有下界的最小
+func compareIntervalLower(comparator VersionComparator, a, b *VersionInterval) (int, error) {
+	switch {
+	case a.Lower == "" && b.Lower == "":
+		return 0, nil
+	case a.Lower == "":
+		return -1, nil
+	case b.Lower == "":
+		return 1, nil
+	}
+	n, err := comparator.Compare(a.Lower, b.Lower)
+	if err != nil || n != 0 {
+		return n, err
+	}
+	// 下界相同的时候包含下界的更小
+	switch {
+	case a.LowerInclusive == b.LowerInclusive:
+		return 0, nil
+	case a.LowerInclusive:
+		return -1, nil
+	default:
+		return 1, nil
+	}
+}
+
+// 比较两个区间的上界，没有上界的最大
+func compareIntervalUpper(comparator VersionComparator, a, b *VersionInterval) (int, error) {
+	switch {
+	case a.Upper == "" && b.Upper == "":
+		return 0, nil
+	case a.Upper == "":
+		return 1, nil
+	case b.Upper == "":
+		return -1, nil
+	}
+	n, err := comparator.Compare(a.Upper, b.Upper)
+	if err != nil || n != 0 {
+		return n, err
+	}
+	// 上界相同的时候包含上界的更大
+	switch {
+	case a.UpperInclusive == b.UpperInclusive:
+		return 0, nil
+	case a.UpperInclusive:
+		return 1, nil
+	default:
+		return -1, nil
+	}
+}
+
+// 判断按下界排好序的两个区间是否重叠或者首尾相接，首尾相接的区间比如 [1.0, 2.0) 和 [2.0, 3.0) 可以合并为一个区间
+func intervalsConnected(comparator VersionComparator, a, b *VersionInterval) (bool, error) {
+	if a.Upper == "" || b.Lower == "" {
+		return true, nil
+	}
+	n, err := comparator.Compare(b.Lower, a.Upper)
+	if err != nil {
+		return false, err
+	}
+	return n < 0 || n == 0 && (a.UpperInclusive || b.LowerInclusive), nil
+}
+
+// 把若干个区间合并为最少的不重叠的区间，并按照版本从小到大排列
+func mergeIntervals(comparator VersionComparator, intervals VersionIntervals) (VersionIntervals, error) {
+	sorted := make(VersionIntervals, 0, len(intervals))
+	for _, interval := range intervals {
+		copied := *interval
+		sorted = append(sorted, &copied)
+	}
+	var err error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		n, e := compareIntervalLower(comparator, sorted[i], sorted[j])
+		if e != nil && err == nil {
+			err = e
+		}
+		return n < 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(VersionIntervals, 0, len(sorted))
+	for _, interval := range sorted {
+		if len(merged) == 0 {
+			merged = append(merged, interval)
+			continue
+		}
+		last := merged[len(merged)-1]
+		connected, err := intervalsConnected(comparator, last, interval)
+		if err != nil {
+			return nil, err
+		}
+		if !connected {
+			merged = append(merged, interval)
+			continue
+		}
+		n, err := compareIntervalUpper(comparator, interval, last)
+		if err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			last.Upper, last.UpperInclusive = interval.Upper, interval.UpperInclusive
+		}
+	}
+	return merged, nil
+}
+
+// 判断区间是否不包含任何版本
+func isEmptyInterval(comparator VersionComparator, interval *VersionInterval) (bool, error) {
+	if interval.Lower == "" || interval.Upper == "" {
+		return false, nil
+	}
+	n, err := comparator.Compare(interval.Lower, interval.Upper)
+	if err != nil {
+		return false, err
+	}
+	return n > 0 || n == 0 && !(interval.LowerInclusive && interval.UpperInclusive), nil
+}
+
+// 求两组区间的交集
+func intersectIntervals(comparator VersionComparator, a, b VersionIntervals) (VersionIntervals, error) {
+	result := make(VersionIntervals, 0)
+	for _, x := range a {
+		for _, y := range b {
+			lower, upper := x, x
+			n, err := compareIntervalLower(comparator, x, y)
+			if err != nil {
+				return nil, err
+			}
+			if n < 0 {
+				lower = y
+			}
+			n, err = compareIntervalUpper(comparator, x, y)
+			if err != nil {
+				return nil, err
+			}
+			if n > 0 {
+				upper = y
+			}
+			interval := &VersionInterval{Lower: lower.Lower, LowerInclusive: lower.LowerInclusive, Upper: upper.Upper, UpperInclusive: upper.UpperInclusive}
+			empty, err := isEmptyInterval(comparator, interval)
+			if err != nil {
+				return nil, err
+			}
+			if !empty {
+				result = append(result, interval)
+			}
+		}
+	}
+	return mergeIntervals(comparator, result)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// eventsToIntervals 把一个范围的事件转换为区间，和 Range.ContainsVersion 的语义一致，
+// 同时返回事件中互相矛盾的地方，比如前面没有任何 introduced 的 fixed 、 introduced 和 fixed 是同一个版本。
+// 区间已经结束之后再出现的 fixed 和 last_affected 是多余的，比如 introduced 1.0.0, fixed 1.2.0, fixed 1.3.0 中的 fixed 1.3.0
+func eventsToIntervals(comparator VersionComparator, events Events) (VersionIntervals, []string, error) {
+	sorted, err := sortEvents(comparator, events)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	intervals := make(VersionIntervals, 0)
+	conflicts := make([]string, 0)
+	var current *VersionInterval
+	var limit string
+	introduced := false
+	for _, event := range sorted {
+		switch {
+		case event.IsIntroduced():
+			if current != nil {
+				// 已经在受影响的状态了，重复的 introduced 是多余的
+				continue
+			}
+			introduced = true
+			current = &VersionInterval{LowerInclusive: true}
+			if event.Introduced != "0" {
+				current.Lower = event.Introduced
+			}
+		case event.IsFixed(), event.IsLastAffected():
+			if current == nil {
+				if introduced {
+					// 没有在受影响的状态，和重复的 introduced 一样是多余的
+					continue
+				}
+				conflicts = append(conflicts, fmt.Sprintf("%s %s has no introduced event before it", eventKind(event), event.version()))
+				continue
+			}
+			current.Upper = event.version()
+			current.UpperInclusive = event.IsLastAffected()
+			if current.Lower != "" && event.IsFixed() {
+				n, err := comparator.Compare(current.Lower, current.Upper)
+				if err != nil {
+					return nil, nil, err
+				}
+				if n == 0 {
+					conflicts = append(conflicts, fmt.Sprintf("version %s is both introduced and fixed", current.Lower))
+					current = nil
+					continue
+				}
+			}
+			intervals = append(intervals, current)
+			current = nil
+		case event.IsLimit():
+			if limit == "" {
+				limit = event.Limit
+			}
+		}
+	}
+	if current != nil {
+		intervals = append(intervals, current)
+	}
+
+	if limit != "" {
+		intervals, err = capIntervals(comparator, intervals, limit)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return intervals, conflicts, nil
+}
+
+// 所有大于等于limit的版本都不受影响
+func capIntervals(comparator VersionComparator, intervals VersionIntervals, limit string) (VersionIntervals, error) {
+	capped := make(VersionIntervals, 0, len(intervals))
+	limitInterval := &VersionInterval{Upper: limit}
+	for _, interval := range intervals {
+		if interval.Lower != "" {
+			n, err := comparator.Compare(interval.Lower, limit)
+			if err != nil {
+				return nil, err
+			}
+			if n >= 0 {
+				continue
+			}
+		}
+		n, err := compareIntervalUpper(comparator, interval, limitInterval)
+		if err != nil {
+			return nil, err
+		}
+		copied := *interval
+		if n > 0 {
+			copied.Upper, copied.UpperInclusive = limit, false
+		}
+		capped = append(capped, &copied)
+	}
+	return capped, nil
+}
+
+func eventKind(event *Event) string {
+	switch {
+	case event.IsIntroduced():
+		return "introduced"
+	case event.IsFixed():
+		return "fixed"
+	case event.IsLastAffected():
+		return "last_affected"
+	default:
+		return "limit"
+	}
+}
+
+// Intervals 把这个SEMVER或者ECOSYSTEM类型的范围转换为不重叠的版本区间
+func (x *Range[DatabaseSpecific]) Intervals(comparator VersionComparator) (VersionIntervals, error) {
+	if x.Type == RangeTypeGit {
+		return nil, fmt.Errorf("range type %s can not be converted to version intervals", x.Type)
+	}
+	intervals, _, err := eventsToIntervals(comparator, x.Events)
+	if err != nil {
+		return nil, err
+	}
+	return mergeIntervals(comparator, intervals)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
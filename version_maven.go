@@ -0,0 +1,224 @@
+package osv_schema
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// MavenComparator 按照Maven的 ComparableVersion 的规则比较版本号，任何字符串都是合法的Maven版本号
+// 参考文档： https://maven.apache.org/pom.html#version-order-specification
+var MavenComparator VersionComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	return parseMavenVersion(a).compare(parseMavenVersion(b)), nil
+})
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// Maven版本号解析之后的一个部分，nil表示缺失的部分
+type mavenItem interface {
+
+	// 和另一个部分比较，other为nil表示和缺失的部分比较
+	compare(other mavenItem) int
+
+	// 是否等价于缺失的部分，比如 0 、 ga 、 final
+	isNull() bool
+}
+
+// 已知的限定符的顺序，不认识的限定符比这些都大，之间按字典序比较
+var mavenQualifiers = []string{"alpha", "beta", "milestone", "rc", "snapshot", "", "sp"}
+
+var mavenQualifierAliases = map[string]string{"ga": "", "final": "", "release": "", "cr": "rc"}
+
+// 空的限定符表示正式版本
+var mavenReleaseQualifier = comparableMavenQualifier("")
+
+func comparableMavenQualifier(qualifier string) string {
+	for i, q := range mavenQualifiers {
+		if q == qualifier {
+			return strconv.Itoa(i)
+		}
+	}
+	return strconv.Itoa(len(mavenQualifiers)) + "-" + qualifier
+}
+
+// 数字部分
+type mavenIntItem struct {
+	value *big.Int
+}
+
+func (x *mavenIntItem) isNull() bool {
+	return x.value.Sign() == 0
+}
+
+func (x *mavenIntItem) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if x.isNull() {
+			return 0
+		}
+		return 1
+	case *mavenIntItem:
+		return x.value.Cmp(o.value)
+	default:
+		return 1
+	}
+}
+
+// 限定符部分
+type mavenStringItem struct {
+	value string
+}
+
+func newMavenStringItem(value string, followedByDigit bool) *mavenStringItem {
+	if followedByDigit && len(value) == 1 {
+		switch value {
+		case "a":
+			value = "alpha"
+		case "b":
+			value = "beta"
+		case "m":
+			value = "milestone"
+		}
+	}
+	if alias, ok := mavenQualifierAliases[value]; ok {
+		value = alias
+	}
+	return &mavenStringItem{value: value}
+}
+
+func (x *mavenStringItem) isNull() bool {
+	return comparableMavenQualifier(x.value) == mavenReleaseQualifier
+}
+
+func (x *mavenStringItem) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		return strings.Compare(comparableMavenQualifier(x.value), mavenReleaseQualifier)
+	case *mavenStringItem:
+		return strings.Compare(comparableMavenQualifier(x.value), comparableMavenQualifier(o.value))
+	default:
+		return -1
+	}
+}
+
+// 用 - 分隔开的子列表
+type mavenListItem struct {
+	items []mavenItem
+}
+
+func (x *mavenListItem) isNull() bool {
+	return len(x.items) == 0
+}
+
+func (x *mavenListItem) compare(other mavenItem) int {
+	switch o := other.(type) {
+	case nil:
+		if len(x.items) == 0 {
+			return 0
+		}
+		return x.items[0].compare(nil)
+	case *mavenIntItem:
+		return -1
+	case *mavenStringItem:
+		return 1
+	case *mavenListItem:
+		for i := 0; i < len(x.items) || i < len(o.items); i++ {
+			var left, right mavenItem
+			if i < len(x.items) {
+				left = x.items[i]
+			}
+			if i < len(o.items) {
+				right = o.items[i]
+			}
+			var n int
+			if left == nil {
+				if right != nil {
+					n = -right.compare(nil)
+				}
+			} else {
+				n = left.compare(right)
+			}
+			if n != 0 {
+				return n
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// 去掉末尾等价于缺失的部分，比如 1.0.0 等价于 1
+func (x *mavenListItem) normalize() {
+	for i := len(x.items) - 1; i >= 0; i-- {
+		item := x.items[i]
+		if item.isNull() {
+			x.items = append(x.items[:i], x.items[i+1:]...)
+		} else if _, ok := item.(*mavenListItem); !ok {
+			break
+		}
+	}
+}
+
+func parseMavenItem(isDigit bool, s string) mavenItem {
+	if isDigit {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			n = big.NewInt(0)
+		}
+		return &mavenIntItem{value: n}
+	}
+	return newMavenStringItem(s, false)
+}
+
+// 和 org.apache.maven.artifact.versioning.ComparableVersion#parseVersion 的逻辑一致
+func parseMavenVersion(version string) *mavenListItem {
+	version = strings.ToLower(strings.TrimSpace(version))
+	root := &mavenListItem{}
+	list := root
+	stack := []*mavenListItem{root}
+	pushList := func() {
+		child := &mavenListItem{}
+		list.items = append(list.items, child)
+		list = child
+		stack = append(stack, child)
+	}
+
+	isDigit := false
+	start := 0
+	for i := 0; i < len(version); i++ {
+		c := version[i]
+		switch {
+		case c == '.' || c == '-':
+			if i == start {
+				list.items = append(list.items, &mavenIntItem{value: big.NewInt(0)})
+			} else {
+				list.items = append(list.items, parseMavenItem(isDigit, version[start:i]))
+			}
+			start = i + 1
+			if c == '-' {
+				pushList()
+			}
+		case c >= '0' && c <= '9':
+			if !isDigit && i > start {
+				list.items = append(list.items, newMavenStringItem(version[start:i], true))
+				start = i
+				pushList()
+			}
+			isDigit = true
+		default:
+			if isDigit && i > start {
+				list.items = append(list.items, parseMavenItem(true, version[start:i]))
+				start = i
+				pushList()
+			}
+			isDigit = false
+		}
+	}
+	if len(version) > start {
+		list.items = append(list.items, parseMavenItem(isDigit, version[start:]))
+	}
+	for i := len(stack) - 1; i >= 0; i-- {
+		stack[i].normalize()
+	}
+	return root
+}
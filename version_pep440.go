@@ -0,0 +1,229 @@
+package osv_schema
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// PEP440Comparator 按照 PEP 440 的规则比较Python包的版本号
+// 参考文档： https://peps.python.org/pep-0440/
+var PEP440Comparator VersionComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	versionA, err := ParsePEP440(a)
+	if err != nil {
+		return 0, err
+	}
+	versionB, err := ParsePEP440(b)
+	if err != nil {
+		return 0, err
+	}
+	return versionA.Compare(versionB), nil
+})
+
+// 和 packaging 库中使用的正则保持一致
+var pep440Regexp = regexp.MustCompile(`^\s*v?` +
+	`(?:(?P<epoch>[0-9]+)!)?` +
+	`(?P<release>[0-9]+(?:\.[0-9]+)*)` +
+	`(?:[-_.]?(?P<pre_l>alpha|a|beta|b|preview|pre|c|rc)[-_.]?(?P<pre_n>[0-9]+)?)?` +
+	`(?:-(?P<post_n1>[0-9]+)|[-_.]?(?P<post_l>post|rev|r)[-_.]?(?P<post_n2>[0-9]+)?)?` +
+	`(?:[-_.]?(?P<dev_l>dev)[-_.]?(?P<dev_n>[0-9]+)?)?` +
+	`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?\s*$`)
+
+// PEP440Version 解析之后的Python版本号
+type PEP440Version struct {
+	Epoch   *big.Int
+	Release []*big.Int
+
+	// 预发布版本的标签，取值为 a 、 b 、 rc ，为空表示不是预发布版本
+	PreLabel  string
+	PreNumber *big.Int
+
+	// 后发布版本号，nil表示不是后发布版本
+	Post *big.Int
+
+	// 开发版本号，nil表示不是开发版本
+	Dev *big.Int
+
+	// 本地版本号的各个部分
+	Local []string
+}
+
+// ParsePEP440 解析Python的版本号，会做规范化处理，比如 1.0-alpha1 会被规范化为 1.0a1
+func ParsePEP440(version string) (*PEP440Version, error) {
+	match := pep440Regexp.FindStringSubmatch(strings.ToLower(version))
+	if match == nil {
+		return nil, fmt.Errorf("invalid PEP 440 version %q", version)
+	}
+	group := func(name string) string {
+		return match[pep440Regexp.SubexpIndex(name)]
+	}
+	number := func(s string) *big.Int {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return big.NewInt(0)
+		}
+		return n
+	}
+
+	result := &PEP440Version{Epoch: big.NewInt(0)}
+	if epoch := group("epoch"); epoch != "" {
+		result.Epoch = number(epoch)
+	}
+	for _, part := range strings.Split(group("release"), ".") {
+		result.Release = append(result.Release, number(part))
+	}
+	if preLabel := group("pre_l"); preLabel != "" {
+		switch preLabel {
+		case "alpha", "a":
+			result.PreLabel = "a"
+		case "beta", "b":
+			result.PreLabel = "b"
+		default:
+			result.PreLabel = "rc"
+		}
+		result.PreNumber = number("0" + group("pre_n"))
+	}
+	if postNumber := group("post_n1"); postNumber != "" {
+		result.Post = number(postNumber)
+	} else if group("post_l") != "" {
+		result.Post = number("0" + group("post_n2"))
+	}
+	if group("dev_l") != "" {
+		result.Dev = number("0" + group("dev_n"))
+	}
+	if local := group("local"); local != "" {
+		result.Local = strings.FieldsFunc(local, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+	return result, nil
+}
+
+// Compare 比较两个版本号
+func (x *PEP440Version) Compare(other *PEP440Version) int {
+	if n := x.Epoch.Cmp(other.Epoch); n != 0 {
+		return n
+	}
+	// 末尾的0不参与比较，1.0 == 1.0.0
+	for i := 0; i < len(x.Release) || i < len(other.Release); i++ {
+		a, b := big.NewInt(0), big.NewInt(0)
+		if i < len(x.Release) {
+			a = x.Release[i]
+		}
+		if i < len(other.Release) {
+			b = other.Release[i]
+		}
+		if n := a.Cmp(b); n != 0 {
+			return n
+		}
+	}
+	if n := comparePEP440Key(x.preKey(), other.preKey()); n != 0 {
+		return n
+	}
+	if n := comparePEP440Key(x.postKey(), other.postKey()); n != 0 {
+		return n
+	}
+	if n := comparePEP440Key(x.devKey(), other.devKey()); n != 0 {
+		return n
+	}
+	return compareLocalVersion(x.Local, other.Local)
+}
+
+// 用于排序的键，rank越小版本越小，rank相同时比较数字
+type pep440Key struct {
+	rank   int
+	number *big.Int
+}
+
+func comparePEP440Key(a, b pep440Key) int {
+	if a.rank != b.rank {
+		return a.rank - b.rank
+	}
+	if a.number == nil || b.number == nil {
+		return 0
+	}
+	return a.number.Cmp(b.number)
+}
+
+// 只有开发版本号的版本比所有的预发布版本都小，没有预发布版本号的版本比所有的预发布版本都大
+func (x *PEP440Version) preKey() pep440Key {
+	switch {
+	case x.PreLabel == "" && x.Post == nil && x.Dev != nil:
+		return pep440Key{rank: -1}
+	case x.PreLabel == "":
+		return pep440Key{rank: 10}
+	case x.PreLabel == "a":
+		return pep440Key{rank: 1, number: x.PreNumber}
+	case x.PreLabel == "b":
+		return pep440Key{rank: 2, number: x.PreNumber}
+	default:
+		return pep440Key{rank: 3, number: x.PreNumber}
+	}
+}
+
+// 没有后发布版本号的版本比有的小
+func (x *PEP440Version) postKey() pep440Key {
+	if x.Post == nil {
+		return pep440Key{rank: -1}
+	}
+	return pep440Key{rank: 0, number: x.Post}
+}
+
+// 没有开发版本号的版本比有的大
+func (x *PEP440Version) devKey() pep440Key {
+	if x.Dev == nil {
+		return pep440Key{rank: 1}
+	}
+	return pep440Key{rank: 0, number: x.Dev}
+}
+
+// 本地版本号：没有本地版本号的比有的小，数字部分比字母部分大，数字按数值比较，字母按字典序比较，前缀相同时更长的更大
+func compareLocalVersion(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		numberA, okA := new(big.Int).SetString(a[i], 10)
+		numberB, okB := new(big.Int).SetString(b[i], 10)
+		var n int
+		switch {
+		case okA && okB:
+			n = numberA.Cmp(numberB)
+		case okA:
+			n = 1
+		case okB:
+			n = -1
+		default:
+			n = strings.Compare(a[i], b[i])
+		}
+		if n != 0 {
+			return n
+		}
+	}
+	return len(a) - len(b)
+}
+
+// String 返回规范化之后的版本号
+func (x *PEP440Version) String() string {
+	builder := strings.Builder{}
+	if x.Epoch.Sign() != 0 {
+		builder.WriteString(x.Epoch.String() + "!")
+	}
+	for i, n := range x.Release {
+		if i > 0 {
+			builder.WriteString(".")
+		}
+		builder.WriteString(n.String())
+	}
+	if x.PreLabel != "" {
+		builder.WriteString(x.PreLabel + x.PreNumber.String())
+	}
+	if x.Post != nil {
+		builder.WriteString(".post" + x.Post.String())
+	}
+	if x.Dev != nil {
+		builder.WriteString(".dev" + x.Dev.String())
+	}
+	if len(x.Local) != 0 {
+		builder.WriteString("+" + strings.Join(x.Local, "."))
+	}
+	return builder.String()
+}
@@ -0,0 +1,91 @@
+package osv_schema
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// RubyGemsComparator 按照 Gem::Version 的规则比较版本号，包含字母的版本是预发布版本，比对应的正式版本小
+// 参考文档： https://guides.rubygems.org/patterns/#semantic-versioning
+var RubyGemsComparator VersionComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	segmentsA, err := parseRubyGemsVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	segmentsB, err := parseRubyGemsVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return compareRubyGemsSegments(segmentsA, segmentsB), nil
+})
+
+// 和 Gem::Version::ANCHORED_VERSION_PATTERN 一致
+var rubyGemsVersionRegexp = regexp.MustCompile(`^\s*[0-9]+(\.[0-9a-zA-Z]+)*(-[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?\s*$`)
+
+var rubyGemsSegmentRegexp = regexp.MustCompile(`[0-9]+|[a-zA-Z]+`)
+
+// 解析之后的版本号的各个部分，元素为 *big.Int 或者 string ，会去掉正式版本部分和预发布部分末尾的0，和 Gem::Version#canonical_segments 一致
+func parseRubyGemsVersion(version string) ([]any, error) {
+	if !rubyGemsVersionRegexp.MatchString(version) {
+		return nil, fmt.Errorf("invalid RubyGems version %q", version)
+	}
+	version = strings.ReplaceAll(strings.TrimSpace(version), "-", ".pre.")
+
+	var release, prerelease []any
+	for _, s := range rubyGemsSegmentRegexp.FindAllString(version, -1) {
+		var segment any = s
+		if n, ok := new(big.Int).SetString(s, 10); ok {
+			segment = n
+		}
+		if _, isString := segment.(string); isString || len(prerelease) != 0 {
+			prerelease = append(prerelease, segment)
+		} else {
+			release = append(release, segment)
+		}
+	}
+	return append(trimRubyGemsZeros(release), trimRubyGemsZeros(prerelease)...), nil
+}
+
+func trimRubyGemsZeros(segments []any) []any {
+	for len(segments) != 0 {
+		n, ok := segments[len(segments)-1].(*big.Int)
+		if !ok || n.Sign() != 0 {
+			break
+		}
+		segments = segments[:len(segments)-1]
+	}
+	return segments
+}
+
+// 缺失的部分当作0，字母比数字小
+func compareRubyGemsSegments(a, b []any) int {
+	zero := big.NewInt(0)
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var left, right any = zero, zero
+		if i < len(a) {
+			left = a[i]
+		}
+		if i < len(b) {
+			right = b[i]
+		}
+		leftString, leftIsString := left.(string)
+		rightString, rightIsString := right.(string)
+		switch {
+		case leftIsString && rightIsString:
+			if n := strings.Compare(leftString, rightString); n != 0 {
+				return n
+			}
+		case leftIsString:
+			return -1
+		case rightIsString:
+			return 1
+		default:
+			if n := left.(*big.Int).Cmp(right.(*big.Int)); n != 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
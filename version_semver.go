@@ -0,0 +1,135 @@
+package osv_schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemverComparator 按照 SemVer 2.0.0 的规则比较版本号，为了兼容Go等包管理器，允许带有 v 前缀，也允许省略 minor 和 patch
+var SemverComparator VersionComparator = VersionComparatorFunc(func(a, b string) (int, error) {
+	versionA, err := ParseSemver(a)
+	if err != nil {
+		return 0, err
+	}
+	versionB, err := ParseSemver(b)
+	if err != nil {
+		return 0, err
+	}
+	return versionA.Compare(versionB), nil
+})
+
+// Semver 解析之后的语义化版本号
+// 参考文档： https://semver.org/spec/v2.0.0.html
+type Semver struct {
+	Major, Minor, Patch uint64
+
+	// 先行版本号，比如 1.0.0-alpha.1 中的 alpha 和 1
+	Prerelease []string
+
+	// 版本编译信息，不参与比较
+	Build string
+}
+
+// ParseSemver 解析语义化版本号，允许带有 v 前缀，允许省略 minor 和 patch
+func ParseSemver(version string) (*Semver, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	result := &Semver{}
+	if index := strings.Index(s, "+"); index >= 0 {
+		result.Build = s[index+1:]
+		s = s[:index]
+	}
+	if index := strings.Index(s, "-"); index >= 0 {
+		prerelease := s[index+1:]
+		s = s[:index]
+		if prerelease == "" {
+			return nil, fmt.Errorf("invalid semver %q: empty prerelease", version)
+		}
+		result.Prerelease = strings.Split(prerelease, ".")
+		for _, identifier := range result.Prerelease {
+			if identifier == "" {
+				return nil, fmt.Errorf("invalid semver %q: empty prerelease identifier", version)
+			}
+		}
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || parts[0] == "" {
+		return nil, fmt.Errorf("invalid semver %q", version)
+	}
+	numbers := []*uint64{&result.Major, &result.Minor, &result.Patch}
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver %q: %w", version, err)
+		}
+		*numbers[i] = n
+	}
+	return result, nil
+}
+
+// Compare 比较两个版本号，编译信息不参与比较
+func (x *Semver) Compare(other *Semver) int {
+	if n := compareUint64(x.Major, other.Major); n != 0 {
+		return n
+	}
+	if n := compareUint64(x.Minor, other.Minor); n != 0 {
+		return n
+	}
+	if n := compareUint64(x.Patch, other.Patch); n != 0 {
+		return n
+	}
+	// 有先行版本号的版本比没有的小
+	switch {
+	case len(x.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(x.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(x.Prerelease) && i < len(other.Prerelease); i++ {
+		if n := comparePrereleaseIdentifier(x.Prerelease[i], other.Prerelease[i]); n != 0 {
+			return n
+		}
+	}
+	return len(x.Prerelease) - len(other.Prerelease)
+}
+
+// String 返回完整的 major.minor.patch 形式的版本号
+func (x *Semver) String() string {
+	s := fmt.Sprintf("%d.%d.%d", x.Major, x.Minor, x.Patch)
+	if len(x.Prerelease) != 0 {
+		s += "-" + strings.Join(x.Prerelease, ".")
+	}
+	if x.Build != "" {
+		s += "+" + x.Build
+	}
+	return s
+}
+
+// 数字的标识符比字母的小，数字之间按数值比较，字母之间按ASCII比较
+func comparePrereleaseIdentifier(a, b string) int {
+	numberA, errA := strconv.ParseUint(a, 10, 64)
+	numberB, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		return compareUint64(numberA, numberB)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,350 @@
+package osv_schema
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// VersionSource 包的所有已发布的版本的数据源，比如本地镜像的包管理器的元数据
+type VersionSource interface {
+
+	// Versions 返回包的所有已发布的版本，顺序不做要求
+	Versions(pkg *Package) ([]string, error)
+}
+
+// VersionSourceFunc 把一个函数适配为 VersionSource
+type VersionSourceFunc func(pkg *Package) ([]string, error)
+
+var _ VersionSource = VersionSourceFunc(nil)
+
+func (x VersionSourceFunc) Versions(pkg *Package) ([]string, error) {
+	return x(pkg)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// NpmPackumentSource 从本地的npm packument文件中读取版本，packument就是 https://registry.npmjs.org/{name} 返回的JSON
+type NpmPackumentSource struct {
+
+	// 镜像的根目录，默认的文件路径为 {Dir}/{name}.json ，带scope的包为 {Dir}/@scope/name.json
+	Dir string
+
+	// 自定义包对应的文件路径，为nil的时候使用默认的路径
+	PathFunc func(pkg *Package) string
+}
+
+var _ VersionSource = &NpmPackumentSource{}
+
+func (x *NpmPackumentSource) Versions(pkg *Package) ([]string, error) {
+	var path string
+	if x.PathFunc != nil {
+		path = x.PathFunc(pkg)
+	} else {
+		var err error
+		if path, err = sourceFilePath(x.Dir, pkg, pkg.Name+".json"); err != nil {
+			return nil, err
+		}
+	}
+	packument := struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}{}
+	if err := readJsonFile(path, &packument); err != nil {
+		return nil, err
+	}
+	return mapKeys(packument.Versions), nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// PyPIJsonSource 从本地的PyPI JSON API的响应中读取版本，即 https://pypi.org/pypi/{name}/json 返回的JSON
+type PyPIJsonSource struct {
+
+	// 镜像的根目录，默认的文件路径为 {Dir}/{normalized name}.json ，包名按照 PEP 503 规范化
+	Dir string
+
+	// 自定义包对应的文件路径，为nil的时候使用默认的路径
+	PathFunc func(pkg *Package) string
+}
+
+var _ VersionSource = &PyPIJsonSource{}
+
+// PEP 503 规定的包名规范化
+var pypiNameRegexp = regexp.MustCompile(`[-_.]+`)
+
+func (x *PyPIJsonSource) Versions(pkg *Package) ([]string, error) {
+	var path string
+	if x.PathFunc != nil {
+		path = x.PathFunc(pkg)
+	} else {
+		var err error
+		if path, err = sourceFilePath(x.Dir, pkg, pypiNameRegexp.ReplaceAllString(strings.ToLower(pkg.Name), "-")+".json"); err != nil {
+			return nil, err
+		}
+	}
+	response := struct {
+		Releases map[string]json.RawMessage `json:"releases"`
+	}{}
+	if err := readJsonFile(path, &response); err != nil {
+		return nil, err
+	}
+	return mapKeys(response.Releases), nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// MavenMetadataSource 从本地的Maven仓库镜像中的 maven-metadata.xml 读取版本
+type MavenMetadataSource struct {
+
+	// 仓库镜像的根目录，默认的文件路径和Maven仓库的布局一致： {Dir}/{groupId中的.替换为/}/{artifactId}/maven-metadata.xml
+	Dir string
+
+	// 自定义包对应的文件路径，为nil的时候使用默认的路径
+	PathFunc func(pkg *Package) string
+}
+
+var _ VersionSource = &MavenMetadataSource{}
+
+func (x *MavenMetadataSource) Versions(pkg *Package) ([]string, error) {
+	var path string
+	if x.PathFunc != nil {
+		path = x.PathFunc(pkg)
+	} else {
+		groupID, artifactID := pkg.GetGroupID(), pkg.GetArtifactID()
+		if groupID == "" || artifactID == "" {
+			return nil, fmt.Errorf("invalid maven package name %q, it should be groupId:artifactId", pkg.Name)
+		}
+		var err error
+		if path, err = sourceFilePath(x.Dir, pkg, strings.ReplaceAll(groupID, ".", "/")+"/"+artifactID+"/maven-metadata.xml"); err != nil {
+			return nil, err
+		}
+	}
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	metadata := struct {
+		Versions []string `xml:"versioning>versions>version"`
+	}{}
+	if err := xml.Unmarshal(fileBytes, &metadata); err != nil {
+		return nil, fmt.Errorf("can not parse %s: %w", path, err)
+	}
+	versions := make([]string, 0, len(metadata.Versions))
+	for _, version := range metadata.Versions {
+		if version = strings.TrimSpace(version); version != "" {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// CratesIndexSource 从本地的crates.io索引仓库中读取版本，索引文件中每一行是一个版本的JSON
+// 参考文档： https://doc.rust-lang.org/cargo/reference/registry-index.html
+type CratesIndexSource struct {
+
+	// 索引仓库的根目录，文件路径和索引仓库的布局一致，比如 {Dir}/se/rd/serde
+	Dir string
+
+	// 是否排除被撤回(yanked)的版本，默认包含
+	ExcludeYanked bool
+
+	// 自定义包对应的文件路径，为nil的时候使用默认的路径
+	PathFunc func(pkg *Package) string
+}
+
+var _ VersionSource = &CratesIndexSource{}
+
+func (x *CratesIndexSource) Versions(pkg *Package) ([]string, error) {
+	var path string
+	if x.PathFunc != nil {
+		path = x.PathFunc(pkg)
+	} else {
+		var err error
+		if path, err = sourceFilePath(x.Dir, pkg, filepath.ToSlash(CratesIndexPath(pkg.Name))); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	versions := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry := struct {
+			Version string `json:"vers"`
+			Yanked  bool   `json:"yanked"`
+		}{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("can not parse %s: %w", path, err)
+		}
+		if entry.Yanked && x.ExcludeYanked {
+			continue
+		}
+		versions = append(versions, entry.Version)
+	}
+	return versions, scanner.Err()
+}
+
+// CratesIndexPath 返回crate在索引仓库中的相对路径，1个和2个字符的crate在 1/ 和 2/ 目录下，3个字符的在 3/{首字母}/ 下，
+// 其它的在 {前两个字符}/{第三四个字符}/ 下
+func CratesIndexPath(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 1:
+		return filepath.Join("1", name)
+	case 2:
+		return filepath.Join("2", name)
+	case 3:
+		return filepath.Join("3", name[:1], name)
+	default:
+		return filepath.Join(name[:2], name[2:4], name)
+	}
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 包在镜像目录下的文件路径，relPath是根据包名拼出来的以 / 分隔的相对路径，包名是不可信的输入，
+// 清理之后是绝对路径或者会跳出镜像目录的时候返回错误，比如 ../../etc/passwd
+func sourceFilePath(dir string, pkg *Package, relPath string) (string, error) {
+	cleaned := filepath.ToSlash(filepath.Clean(filepath.FromSlash(relPath)))
+	if !isLocalSlashPath(cleaned) {
+		return "", fmt.Errorf("invalid package name %q", pkg.Name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(cleaned)), nil
+}
+
+func readJsonFile(path string, v any) error {
+	fileBytes, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fileBytes, v); err != nil {
+		return fmt.Errorf("can not parse %s: %w", path, err)
+	}
+	return nil
+}
+
+func mapKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// InvalidVersion 展开版本列表时无法解析而被跳过的已发布的版本，真实的npm、PyPI元数据中常有不符合规范的老版本
+type InvalidVersion struct {
+	Package *Package
+	Version string
+	Err     error
+}
+
+func (x *InvalidVersion) String() string {
+	return fmt.Sprintf("%s %s: %s", x.Package.Name, x.Version, x.Err.Error())
+}
+
+// ExpandVersions 根据包的所有已发布的版本，把SEMVER和ECOSYSTEM类型的范围展开为受影响的版本的列表，覆盖原来的 Versions ，
+// 版本按照包管理器的规则从小到大排列，没有SEMVER和ECOSYSTEM类型的范围的话不做任何修改。
+// 无法解析的已发布的版本会被跳过，通过第一个返回值报告，不会导致整个展开失败
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) ExpandVersions(source VersionSource) ([]*InvalidVersion, error) {
+	if x.Package == nil {
+		return nil, fmt.Errorf("affected has no package")
+	}
+	hasVersionRange := false
+	for _, r := range x.Ranges {
+		if r != nil && r.Type != RangeTypeGit {
+			hasVersionRange = true
+		}
+	}
+	if !hasVersionRange {
+		return nil, nil
+	}
+
+	published, err := source.Versions(x.Package)
+	if err != nil {
+		return nil, err
+	}
+	sortComparator, err := GetVersionComparator(x.Package.Ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0)
+	invalidVersions := make([]*InvalidVersion, 0)
+	for _, version := range published {
+		affected, err := x.expandVersion(sortComparator, version)
+		if err != nil {
+			invalidVersions = append(invalidVersions, &InvalidVersion{Package: x.Package, Version: version, Err: err})
+			continue
+		}
+		if affected {
+			versions = append(versions, version)
+		}
+	}
+
+	if err := SortVersions(sortComparator, versions); err != nil {
+		return nil, err
+	}
+	x.Versions = versions
+	return invalidVersions, nil
+}
+
+// 判断已发布的版本是否在某个范围中，版本在包管理器的规则下或者在任意一个范围的比较器下无法解析的时候返回错误
+func (x *Affected[EcosystemSpecific, DatabaseSpecific]) expandVersion(sortComparator VersionComparator, version string) (bool, error) {
+	// 排序时会使用包管理器的比较器，提前排除掉无法解析的版本
+	if _, err := sortComparator.Compare(version, version); err != nil {
+		return false, err
+	}
+	affected := false
+	for _, r := range x.Ranges {
+		if r == nil || r.Type == RangeTypeGit {
+			continue
+		}
+		comparator, err := x.versionComparator(r.Type)
+		if err != nil {
+			return false, err
+		}
+		ok, err := r.ContainsVersion(comparator, version)
+		if err != nil {
+			return false, err
+		}
+		affected = affected || ok
+	}
+	return affected, nil
+}
+
+// ExpandVersions 展开所有影响范围的版本列表，返回所有被跳过的无法解析的版本，@see Affected.ExpandVersions
+func (x *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ExpandVersions(source VersionSource) ([]*InvalidVersion, error) {
+	invalidVersions := make([]*InvalidVersion, 0)
+	for _, affected := range x.Affected {
+		if affected == nil || affected.Package == nil {
+			continue
+		}
+		invalid, err := affected.ExpandVersions(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", x.ID, err)
+		}
+		invalidVersions = append(invalidVersions, invalid...)
+	}
+	return invalidVersions, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,61 @@
+package osv_schema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffected_ExpandVersions(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "tensorflow.json"), []byte(`{"releases": {"2.7.0": [], "2.7.2": [], "2.8.0": [], "2.8.1": [], "2.10.0": [], "2.7.1": [], "not-a-version": [], "2.7.0-legacy!": []}}`), 0644))
+
+	r, err := UnmarshalFromJsonFile[any, any]("test_data/GHSA-vxv8-r8q2-63xw.json")
+	assert.Nil(t, err)
+	affected := r.Affected[0]
+	invalidVersions, err := affected.ExpandVersions(&PyPIJsonSource{Dir: dir})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2.7.0", "2.7.1"}, affected.Versions)
+	// 无法解析的版本被跳过并报告
+	invalid := make([]string, 0)
+	for _, invalidVersion := range invalidVersions {
+		invalid = append(invalid, invalidVersion.Version)
+	}
+	assert.ElementsMatch(t, []string{"not-a-version", "2.7.0-legacy!"}, invalid)
+
+	crates := filepath.Join(dir, "crates")
+	assert.Nil(t, os.MkdirAll(filepath.Join(crates, "se", "rd"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(crates, "se", "rd", "serde"), []byte("{\"vers\":\"1.0.0\"}\n{\"vers\":\"1.0.1\",\"yanked\":true}\n"), 0644))
+	versions, err := (&CratesIndexSource{Dir: crates, ExcludeYanked: true}).Versions(&Package{Ecosystem: EcosystemCratesIo, Name: "serde"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0"}, versions)
+
+	maven := filepath.Join(dir, "maven", "org", "example", "foo")
+	assert.Nil(t, os.MkdirAll(maven, 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(maven, "maven-metadata.xml"), []byte(`<metadata><versioning><versions><version>1.0</version><version>1.1</version></versions></versioning></metadata>`), 0644))
+	versions, err = (&MavenMetadataSource{Dir: filepath.Join(dir, "maven")}).Versions(&Package{Ecosystem: EcosystemMaven, Name: "org.example:foo"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0", "1.1"}, versions)
+
+	// 包名不能跳出镜像目录
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "secret.json"), []byte(`{"versions": {"1.0.0": {}}}`), 0644))
+	npm := filepath.Join(dir, "npm")
+	assert.Nil(t, os.MkdirAll(filepath.Join(npm, "@scope"), 0755))
+	assert.Nil(t, os.WriteFile(filepath.Join(npm, "@scope", "name.json"), []byte(`{"versions": {"1.0.0": {}}}`), 0644))
+	versions, err = (&NpmPackumentSource{Dir: npm}).Versions(&Package{Ecosystem: EcosystemNpm, Name: "@scope/name"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1.0.0"}, versions)
+	for _, name := range []string{"../secret", "/etc/passwd", "@scope/../../secret"} {
+		_, err = (&NpmPackumentSource{Dir: npm}).Versions(&Package{Ecosystem: EcosystemNpm, Name: name})
+		assert.NotNil(t, err, name)
+		assert.False(t, os.IsNotExist(err), name)
+	}
+	_, err = (&CratesIndexSource{Dir: crates}).Versions(&Package{Ecosystem: EcosystemCratesIo, Name: "../../secret"})
+	assert.NotNil(t, err)
+	assert.False(t, os.IsNotExist(err))
+	_, err = (&MavenMetadataSource{Dir: maven}).Versions(&Package{Ecosystem: EcosystemMaven, Name: "/etc:passwd"})
+	assert.NotNil(t, err)
+	assert.False(t, os.IsNotExist(err))
+}
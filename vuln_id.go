@@ -0,0 +1,204 @@
+package osv_schema
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// VulnDatabase 表示一个漏洞库，漏洞库通过漏洞编号的前缀来识别，比如 GHSA-xxxx-xxxx-xxxx 是GitHub的漏洞库
+// 参考文档： https://ossf.github.io/osv-schema/#id-modified-fields
+type VulnDatabase struct {
+
+	// 漏洞编号的前缀，不包含后面的 - ，比如 GHSA
+	Prefix string
+
+	// 漏洞库的名字，比如 GitHub Security Advisory
+	Name string
+
+	// 用于校验漏洞编号格式的正则，匹配的是规范化之后的编号，为nil的话不校验
+	Pattern *regexp.Regexp
+
+	// 漏洞详情页的链接模板，%s 会被替换为规范化之后的编号，为空的话表示没有详情页
+	URLTemplate string
+
+	// 编号中前缀后面的部分是否是小写的，比如 GHSA-vxv8-r8q2-63xw ，默认转为大写，比如 UBUNTU-CVE-2024-1
+	LowerCaseBody bool
+}
+
+// Canonicalize 把漏洞编号规范化，前缀转为大写，前缀后面的部分根据漏洞库的规则转换大小写
+func (x *VulnDatabase) Canonicalize(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) <= len(x.Prefix) {
+		return strings.ToUpper(id)
+	}
+	body := id[len(x.Prefix):]
+	if x.LowerCaseBody {
+		body = strings.ToLower(body)
+	} else {
+		body = strings.ToUpper(body)
+	}
+	return x.Prefix + body
+}
+
+// Validate 校验规范化之后的漏洞编号的格式
+func (x *VulnDatabase) Validate(id string) error {
+	if x.Pattern != nil && !x.Pattern.MatchString(id) {
+		return fmt.Errorf("invalid %s id %q, it should match %s", x.Prefix, id, x.Pattern.String())
+	}
+	return nil
+}
+
+// URL 返回漏洞详情页的链接，没有详情页的话返回空字符串
+func (x *VulnDatabase) URL(id string) string {
+	if x.URLTemplate == "" {
+		return ""
+	}
+	return fmt.Sprintf(x.URLTemplate, id)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 漏洞库没有自己的详情页的时候使用osv.dev的详情页
+const osvDevVulnURLTemplate = "https://osv.dev/vulnerability/%s"
+
+// GHSA和CGA的编号中使用的字符集
+const ghsaPattern = `(-[23456789cfghjmpqrvwx]{4}){3}$`
+
+var (
+	vulnDatabasesLock sync.RWMutex
+	vulnDatabases     = map[string]*VulnDatabase{}
+)
+
+func init() {
+	for _, database := range []*VulnDatabase{
+		{Prefix: "CVE", Name: "Common Vulnerabilities and Exposures", Pattern: regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`), URLTemplate: "https://www.cve.org/CVERecord?id=%s"},
+		{Prefix: "GHSA", Name: "GitHub Security Advisory", Pattern: regexp.MustCompile(`^GHSA` + ghsaPattern), URLTemplate: "https://github.com/advisories/%s", LowerCaseBody: true},
+		{Prefix: "GO", Name: "Go Vulnerability Database", Pattern: regexp.MustCompile(`^GO-\d{4}-\d{4,}$`), URLTemplate: "https://pkg.go.dev/vuln/%s"},
+		{Prefix: "PYSEC", Name: "PyPI Advisory Database", Pattern: regexp.MustCompile(`^PYSEC-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "RUSTSEC", Name: "RustSec Advisory Database", Pattern: regexp.MustCompile(`^RUSTSEC-\d{4}-\d{4,}$`), URLTemplate: "https://rustsec.org/advisories/%s.html"},
+		{Prefix: "OSV", Name: "OSS-Fuzz", Pattern: regexp.MustCompile(`^OSV-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "DSA", Name: "Debian Security Advisory", Pattern: regexp.MustCompile(`^DSA-\d+(-\d+)?$`), URLTemplate: "https://security-tracker.debian.org/tracker/%s"},
+		{Prefix: "DLA", Name: "Debian LTS Advisory", Pattern: regexp.MustCompile(`^DLA-\d+(-\d+)?$`), URLTemplate: "https://security-tracker.debian.org/tracker/%s"},
+		{Prefix: "DTSA", Name: "Debian Testing Security Advisory", Pattern: regexp.MustCompile(`^DTSA-\d+(-\d+)?$`), URLTemplate: "https://security-tracker.debian.org/tracker/%s"},
+		{Prefix: "USN", Name: "Ubuntu Security Notice", Pattern: regexp.MustCompile(`^USN-\d+-\d+$`), URLTemplate: "https://ubuntu.com/security/notices/%s"},
+		{Prefix: "UBUNTU", Name: "Ubuntu CVE Tracker", Pattern: regexp.MustCompile(`^UBUNTU-CVE-\d{4}-\d{4,}$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "ALSA", Name: "AlmaLinux Security Advisory", Pattern: regexp.MustCompile(`^ALSA-\d{4}:\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "RLSA", Name: "Rocky Linux Security Advisory", Pattern: regexp.MustCompile(`^RLSA-\d{4}:\d+$`), URLTemplate: "https://errata.rockylinux.org/%s"},
+		{Prefix: "RHSA", Name: "Red Hat Security Advisory", Pattern: regexp.MustCompile(`^RHSA-\d{4}:\d+$`), URLTemplate: "https://access.redhat.com/errata/%s"},
+		{Prefix: "SUSE", Name: "SUSE Security Advisory", Pattern: regexp.MustCompile(`^SUSE-[A-Z]{2}-\d{4}:\d+(-\d+)?$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "MGASA", Name: "Mageia Security Advisory", Pattern: regexp.MustCompile(`^MGASA-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "OESA", Name: "openEuler Security Advisory", Pattern: regexp.MustCompile(`^OESA-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "MAL", Name: "OpenSSF Malicious Packages", Pattern: regexp.MustCompile(`^MAL-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "BIT", Name: "Bitnami Vulnerability Database", Pattern: regexp.MustCompile(`^BIT-[a-z0-9._-]+-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate, LowerCaseBody: true},
+		{Prefix: "CGA", Name: "Chainguard Security Advisory", Pattern: regexp.MustCompile(`^CGA` + ghsaPattern), URLTemplate: osvDevVulnURLTemplate, LowerCaseBody: true},
+		{Prefix: "GSD", Name: "Global Security Database", Pattern: regexp.MustCompile(`^GSD-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "HSEC", Name: "Haskell Security Advisory", Pattern: regexp.MustCompile(`^HSEC-\d{4}-\d{4,}$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "PSF", Name: "Python Software Foundation Advisory", Pattern: regexp.MustCompile(`^PSF-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "RSEC", Name: "R Security Advisory", Pattern: regexp.MustCompile(`^RSEC-\d{4}-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+		{Prefix: "ASB", Name: "Android Security Bulletin", Pattern: regexp.MustCompile(`^ASB-A-\d+$`), URLTemplate: osvDevVulnURLTemplate},
+	} {
+		RegisterVulnDatabase(database)
+	}
+}
+
+// RegisterVulnDatabase 注册一个漏洞库，如果前缀已经注册过了则覆盖之前的，可以用来支持内部的漏洞库
+func RegisterVulnDatabase(database *VulnDatabase) {
+	vulnDatabasesLock.Lock()
+	defer vulnDatabasesLock.Unlock()
+	vulnDatabases[strings.ToUpper(database.Prefix)] = database
+}
+
+// GetVulnDatabase 根据前缀获取漏洞库，没有注册的话返回nil
+func GetVulnDatabase(prefix string) *VulnDatabase {
+	vulnDatabasesLock.RLock()
+	defer vulnDatabasesLock.RUnlock()
+	return vulnDatabases[strings.ToUpper(prefix)]
+}
+
+// DetectVulnDatabase 根据漏洞编号的前缀识别漏洞库，不校验编号的格式，识别不出来的话返回nil
+func DetectVulnDatabase(id string) *VulnDatabase {
+	upper := strings.ToUpper(strings.TrimSpace(id))
+	vulnDatabasesLock.RLock()
+	defer vulnDatabasesLock.RUnlock()
+	// 前缀可能会有包含关系，取最长的那个
+	var result *VulnDatabase
+	for prefix, database := range vulnDatabases {
+		if strings.HasPrefix(upper, prefix+"-") && (result == nil || len(prefix) > len(result.Prefix)) {
+			result = database
+		}
+	}
+	return result
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// ErrUnknownVulnDatabase 漏洞编号的前缀没有对应的漏洞库
+var ErrUnknownVulnDatabase = errors.New("unknown vulnerability database")
+
+// VulnID 解析之后的漏洞编号
+type VulnID struct {
+
+	// 规范化之后的编号
+	ID string
+
+	// 编号所属的漏洞库
+	Database *VulnDatabase
+}
+
+// ParseVulnID 解析漏洞编号，识别出所属的漏洞库并校验格式，识别不出漏洞库的时候返回 ErrUnknownVulnDatabase
+func ParseVulnID(id string) (*VulnID, error) {
+	database := DetectVulnDatabase(id)
+	if database == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownVulnDatabase, id)
+	}
+	canonical := database.Canonicalize(id)
+	if err := database.Validate(canonical); err != nil {
+		return nil, err
+	}
+	return &VulnID{ID: canonical, Database: database}, nil
+}
+
+// Prefix 返回编号所属的漏洞库的前缀，比如 GHSA
+func (x *VulnID) Prefix() string {
+	return x.Database.Prefix
+}
+
+// URL 返回漏洞详情页的链接
+func (x *VulnID) URL() string {
+	return x.Database.URL(x.ID)
+}
+
+func (x *VulnID) String() string {
+	return x.ID
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 过滤出属于给定漏洞库的编号
+func filterVulnIDsByDatabase(ids []string, prefix string) []string {
+	slice := make([]string, 0)
+	for _, id := range ids {
+		if database := DetectVulnDatabase(id); database != nil && strings.EqualFold(database.Prefix, prefix) {
+			slice = append(slice, id)
+		}
+	}
+	return slice
+}
+
+// 解析所有能够解析的编号，解析不了的会被忽略
+func parseVulnIDs(ids []string) []*VulnID {
+	slice := make([]*VulnID, 0, len(ids))
+	for _, id := range ids {
+		if vulnID, err := ParseVulnID(id); err == nil {
+			slice = append(slice, vulnID)
+		}
+	}
+	return slice
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
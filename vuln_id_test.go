@@ -0,0 +1,49 @@
+package osv_schema
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseVulnID(t *testing.T) {
+	id, err := ParseVulnID("ghsa-VXV8-R8Q2-63XW")
+	assert.Nil(t, err)
+	assert.Equal(t, "GHSA-vxv8-r8q2-63xw", id.ID)
+	assert.Equal(t, "GHSA", id.Prefix())
+	assert.Equal(t, "https://github.com/advisories/GHSA-vxv8-r8q2-63xw", id.URL())
+
+	id, err = ParseVulnID("UBUNTU-CVE-2022-35981")
+	assert.Nil(t, err)
+	assert.Equal(t, "UBUNTU", id.Prefix())
+
+	_, err = ParseVulnID("CVE-22-1")
+	assert.NotNil(t, err)
+
+	_, err = ParseVulnID("FOO-2022-1")
+	assert.ErrorIs(t, err, ErrUnknownVulnDatabase)
+
+	id, err = ParseVulnID("ubuntu-cve-2024-1234")
+	assert.Nil(t, err)
+	assert.Equal(t, "UBUNTU-CVE-2024-1234", id.ID)
+
+	id, err = ParseVulnID("suse-su-2024:1234-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "SUSE-SU-2024:1234-1", id.ID)
+
+	RegisterVulnDatabase(&VulnDatabase{Prefix: "FOO", Name: "Foo", URLTemplate: "https://foo.example.com/%s"})
+	t.Cleanup(func() {
+		vulnDatabasesLock.Lock()
+		defer vulnDatabasesLock.Unlock()
+		delete(vulnDatabases, "FOO")
+	})
+	id, err = ParseVulnID("foo-2022-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://foo.example.com/FOO-2022-1", id.URL())
+}
+
+func TestAliases_ByDatabase(t *testing.T) {
+	aliases := Aliases{"cve-2022-35981", "GHSA-vxv8-r8q2-63xw", "PYSEC-2022-1"}
+	assert.Equal(t, Aliases{"GHSA-vxv8-r8q2-63xw"}, aliases.ByDatabase("ghsa"))
+	assert.Equal(t, "CVE-2022-35981", aliases.GetCVE())
+	assert.Len(t, aliases.VulnIDs(), 3)
+}
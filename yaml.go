@@ -0,0 +1,234 @@
+package osv_schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// UnmarshalFromYaml 从YAML中反序列化，YAML会先被转换为JSON，再按照JSON的方式反序列化，
+// 所以和 UnmarshalFromJson 的行为完全一致，包括 UnknownFields 以及 EcosystemSpecific 、 DatabaseSpecific 的解析
+func UnmarshalFromYaml[EcosystemSpecific, DatabaseSpecific any](yamlBytes []byte) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	jsonBytes, err := yamlToJson(yamlBytes)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalFromJson[EcosystemSpecific, DatabaseSpecific](jsonBytes)
+}
+
+// UnmarshalFromYamlFile 从YAML文件中反序列化，@see UnmarshalFromYaml
+func UnmarshalFromYamlFile[EcosystemSpecific, DatabaseSpecific any](yamlFilePath string) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	fileBytes, err := readFileDecompressed(yamlFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalFromYaml[EcosystemSpecific, DatabaseSpecific](fileBytes)
+}
+
+// UnmarshalFromYamlStrict 从YAML中反序列化，严格模式，遇到不认识的字段时返回 *UnknownFieldsError
+func UnmarshalFromYamlStrict[EcosystemSpecific, DatabaseSpecific any](yamlBytes []byte) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	jsonBytes, err := yamlToJson(yamlBytes)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalFromJsonStrict[EcosystemSpecific, DatabaseSpecific](jsonBytes)
+}
+
+// UnmarshalFromYamlFileStrict 从YAML文件中反序列化，严格模式，@see UnmarshalFromYamlStrict
+func UnmarshalFromYamlFileStrict[EcosystemSpecific, DatabaseSpecific any](yamlFilePath string) (*OsvSchema[EcosystemSpecific, DatabaseSpecific], error) {
+	fileBytes, err := readFileDecompressed(yamlFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalFromYamlStrict[EcosystemSpecific, DatabaseSpecific](fileBytes)
+}
+
+// MarshalToYaml 序列化为YAML，会先按照JSON的方式序列化再转换为YAML，字段的顺序和JSON一致，
+// published 、 modified 、 withdrawn 会输出为不带引号的YAML时间戳，看起来像其它类型的字符串会加上引号
+func MarshalToYaml[EcosystemSpecific, DatabaseSpecific any](osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific]) ([]byte, error) {
+	jsonBytes, err := json.Marshal(osvSchema)
+	if err != nil {
+		return nil, err
+	}
+	return jsonToYaml(jsonBytes)
+}
+
+// MarshalToYamlFile 序列化为YAML并写入到文件中，@see MarshalToYaml
+func MarshalToYamlFile[EcosystemSpecific, DatabaseSpecific any](osvSchema *OsvSchema[EcosystemSpecific, DatabaseSpecific], yamlFilePath string) error {
+	yamlBytes, err := MarshalToYaml(osvSchema)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(yamlFilePath, yamlBytes, 0644)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 把YAML转换为等价的JSON
+func yamlToJson(yamlBytes []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(yamlBytes, &document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		return nil, fmt.Errorf("empty yaml document")
+	}
+	converter := &yamlConverter{budget: yamlExpandedNodesBase + yamlExpandedNodesRatio*countYamlNodes(&document)}
+	value, err := converter.toValue(&document)
+	if err != nil {
+		return nil, err
+	}
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buffer.Bytes(), "\n"), nil
+}
+
+// 展开别名之后允许的节点数，是文档本身的节点数的若干倍再加上一个基数，超过的话认为是恶意构造的文档（billion laughs），
+// 解析到 yaml.Node 的时候不会经过yaml.v3自己的别名展开限制，所以这里需要自己计数
+const (
+	yamlExpandedNodesBase  = 10000
+	yamlExpandedNodesRatio = 10
+)
+
+// 不展开别名，统计文档本身的节点数
+func countYamlNodes(node *yaml.Node) int {
+	count := 1
+	for _, child := range node.Content {
+		count += countYamlNodes(child)
+	}
+	return count
+}
+
+// 把 yaml.Node 转换为JSON的值，budget是剩余允许展开的节点数
+type yamlConverter struct {
+	budget int
+}
+
+func (x *yamlConverter) toValue(node *yaml.Node) (any, error) {
+	x.budget--
+	if x.budget < 0 {
+		return nil, fmt.Errorf("line %d: document contains too many alias expansions", node.Line)
+	}
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return x.toValue(node.Content[0])
+	case yaml.AliasNode:
+		return x.toValue(node.Alias)
+	case yaml.SequenceNode:
+		values := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			value, err := x.toValue(child)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case yaml.MappingNode:
+		values := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, valueNode := node.Content[i], node.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: mapping key must be a scalar", key.Line)
+			}
+			value, err := x.toValue(valueNode)
+			if err != nil {
+				return nil, err
+			}
+			values[key.Value] = value
+		}
+		return values, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!str", "!!binary":
+			return node.Value, nil
+		case "!!null":
+			return nil, nil
+		case "!!int":
+			// 使用 json.Number ，避免大整数被转换为浮点数丢失精度
+			var n int64
+			if err := node.Decode(&n); err == nil {
+				return json.Number(strconv.FormatInt(n, 10)), nil
+			}
+			if i, ok := new(big.Int).SetString(node.Value, 10); ok {
+				return json.Number(i.String()), nil
+			}
+			return nil, fmt.Errorf("line %d: invalid integer %q", node.Line, node.Value)
+		case "!!timestamp":
+			var t time.Time
+			if err := node.Decode(&t); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			return t.Format(time.RFC3339Nano), nil
+		default:
+			var value any
+			if err := node.Decode(&value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("line %d: unsupported yaml node", node.Line)
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// 这些字段的值是时间，输出YAML时使用时间戳而不是字符串
+var yamlTimestampFields = map[string]bool{"published": true, "modified": true, "withdrawn": true}
+
+// 把JSON转换为块格式的YAML
+func jsonToYaml(jsonBytes []byte) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(jsonBytes, &document); err != nil {
+		return nil, err
+	}
+	resetYamlStyle(&document)
+	if len(document.Content) != 0 && document.Content[0].Kind == yaml.MappingNode {
+		root := document.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key, value := root.Content[i], root.Content[i+1]
+			if !yamlTimestampFields[key.Value] || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339Nano, value.Value); err == nil {
+				value.Tag = "!!timestamp"
+			}
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// JSON解析出来的节点都是流格式和带引号的，去掉之后由编码器决定是否需要加引号
+func resetYamlStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYamlStyle(child)
+	}
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
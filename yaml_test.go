@@ -0,0 +1,89 @@
+package osv_schema
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalFromYaml(t *testing.T) {
+	yamlBytes := []byte(`id: PYSEC-2021-1
+modified: 2021-07-22 00:00:00
+published: 2021-07-20T12:30:00Z
+aliases:
+- CVE-2021-1
+affected:
+- package:
+    name: foo
+    ecosystem: PyPI
+  ranges:
+  - type: ECOSYSTEM
+    events:
+    - introduced: "0"
+    - fixed: "1.10"
+  versions:
+  - "1.0"
+  ecosystem_specific:
+    big: 12345678901234567890
+    nested: {a: [1, true, null]}
+x_extra: kept
+`)
+	r, err := UnmarshalFromYaml[map[string]any, any](yamlBytes)
+	assert.Nil(t, err)
+	assert.Equal(t, "PYSEC-2021-1", r.ID)
+	assert.Equal(t, time.Date(2021, 7, 22, 0, 0, 0, 0, time.UTC), r.Modified.UTC())
+	assert.Equal(t, "1.10", r.Affected[0].Ranges[0].Events[1].Fixed)
+	assert.Equal(t, []string{"1.0"}, r.Affected[0].Versions)
+	assert.Equal(t, json.RawMessage(`"kept"`), r.UnknownFields["x_extra"])
+
+	_, err = UnmarshalFromYamlStrict[map[string]any, any](yamlBytes)
+	assert.NotNil(t, err)
+
+	out, err := MarshalToYaml(r)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(out), "modified: 2021-07-22T00:00:00Z\n"), string(out))
+	assert.True(t, strings.Contains(string(out), `introduced: "0"`), string(out))
+
+	again, err := UnmarshalFromYaml[map[string]any, any](out)
+	assert.Nil(t, err)
+	expected, _ := json.Marshal(r)
+	actual, _ := json.Marshal(again)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func TestMarshalToYamlFile(t *testing.T) {
+	r, err := UnmarshalFromJsonFile[any, any]("test_data/GHSA-vxv8-r8q2-63xw.json")
+	assert.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "GHSA-vxv8-r8q2-63xw.yaml")
+	assert.Nil(t, MarshalToYamlFile(r, path))
+	again, err := UnmarshalFromYamlFile[any, any](path)
+	assert.Nil(t, err)
+	expected, _ := json.Marshal(r)
+	actual, _ := json.Marshal(again)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+func TestUnmarshalFromYamlAliasBomb(t *testing.T) {
+	yamlBytes := []byte(`id: &a ["lol","lol","lol","lol","lol","lol","lol","lol","lol"]
+b: &b [*a,*a,*a,*a,*a,*a,*a,*a,*a]
+c: &c [*b,*b,*b,*b,*b,*b,*b,*b,*b]
+d: &d [*c,*c,*c,*c,*c,*c,*c,*c,*c]
+e: &e [*d,*d,*d,*d,*d,*d,*d,*d,*d]
+f: &f [*e,*e,*e,*e,*e,*e,*e,*e,*e]
+g: &g [*f,*f,*f,*f,*f,*f,*f,*f,*f]
+h: &h [*g,*g,*g,*g,*g,*g,*g,*g,*g]
+i: &i [*h,*h,*h,*h,*h,*h,*h,*h,*h]
+`)
+	_, err := UnmarshalFromYaml[any, any](yamlBytes)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "alias")
+
+	// 少量的别名可以正常展开
+	r, err := UnmarshalFromYaml[any, any]([]byte("id: &id GHSA-1\naliases: [*id]\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, Aliases{"GHSA-1"}, r.Aliases)
+}
@@ -0,0 +1,158 @@
+package osv_schema
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ------------------------------------------------- --------------------------------------------------------------------
+
+// ZipOptions 读取压缩包的选项，传nil的话使用默认选项
+type ZipOptions struct {
+
+	// ForEach 时并行解析的文件数，小于等于1的时候按照压缩包中的顺序逐个解析
+	Concurrency int
+
+	// 是否使用严格模式，遇到不认识的字段时返回 *UnknownFieldsError ，@see UnmarshalFromJsonStrict
+	Strict bool
+}
+
+// ZipReader 读取osv.dev导出的压缩包，比如 https://osv-vulnerabilities.storage.googleapis.com/PyPI/all.zip ，
+// 压缩包中的每个 .json 文件是一条漏洞记录，记录只有在迭代到的时候才会被解压和解析
+type ZipReader[EcosystemSpecific, DatabaseSpecific any] struct {
+	reader  *zip.Reader
+	closer  io.Closer
+	options ZipOptions
+
+	// 压缩包中的JSON文件
+	files []*zip.File
+
+	// Next 下一个要读取的文件的下标
+	next int
+}
+
+// NewZipReader 从 io.ReaderAt 中读取压缩包，size是压缩包的大小
+func NewZipReader[EcosystemSpecific, DatabaseSpecific any](readerAt io.ReaderAt, size int64, options *ZipOptions) (*ZipReader[EcosystemSpecific, DatabaseSpecific], error) {
+	reader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, err
+	}
+	return newZipReader[EcosystemSpecific, DatabaseSpecific](reader, nil, options), nil
+}
+
+// OpenZipFile 打开本地的压缩包，读取完之后需要调用 Close 关闭文件
+func OpenZipFile[EcosystemSpecific, DatabaseSpecific any](zipFilePath string, options *ZipOptions) (*ZipReader[EcosystemSpecific, DatabaseSpecific], error) {
+	readCloser, err := zip.OpenReader(zipFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return newZipReader[EcosystemSpecific, DatabaseSpecific](&readCloser.Reader, readCloser, options), nil
+}
+
+func newZipReader[EcosystemSpecific, DatabaseSpecific any](reader *zip.Reader, closer io.Closer, options *ZipOptions) *ZipReader[EcosystemSpecific, DatabaseSpecific] {
+	x := &ZipReader[EcosystemSpecific, DatabaseSpecific]{reader: reader, closer: closer}
+	if options != nil {
+		x.options = *options
+	}
+	for _, file := range reader.File {
+		if !file.FileInfo().IsDir() && strings.EqualFold(path.Ext(file.Name), ".json") {
+			x.files = append(x.files, file)
+		}
+	}
+	return x
+}
+
+// Close 关闭压缩包文件，不是通过 OpenZipFile 创建的话什么也不做
+func (x *ZipReader[EcosystemSpecific, DatabaseSpecific]) Close() error {
+	if x.closer == nil {
+		return nil
+	}
+	return x.closer.Close()
+}
+
+// Paths 返回压缩包中所有的记录文件的路径
+func (x *ZipReader[EcosystemSpecific, DatabaseSpecific]) Paths() []string {
+	paths := make([]string, 0, len(x.files))
+	for _, file := range x.files {
+		paths = append(paths, file.Name)
+	}
+	return paths
+}
+
+// Len 返回压缩包中的记录文件的数量
+func (x *ZipReader[EcosystemSpecific, DatabaseSpecific]) Len() int {
+	return len(x.files)
+}
+
+// Next 按照压缩包中的顺序读取下一条记录，读取完的时候返回 io.EOF ，某个文件解析失败的时候返回 *FileError ，可以继续调用 Next 读取后面的记录
+func (x *ZipReader[EcosystemSpecific, DatabaseSpecific]) Next() (*FileRecord[EcosystemSpecific, DatabaseSpecific], error) {
+	if x.next >= len(x.files) {
+		return nil, io.EOF
+	}
+	file := x.files[x.next]
+	x.next++
+	return x.readFile(file)
+}
+
+// ForEach 遍历压缩包中的所有记录，设置了 ZipOptions.Concurrency 的时候会并行解析，这时 fn 会被并发调用，调用的顺序也不确定。
+// 文件解析失败的时候 fn 的 err 参数为 *FileError ，fn 返回错误的时候停止遍历并返回这个错误
+func (x *ZipReader[EcosystemSpecific, DatabaseSpecific]) ForEach(fn func(record *FileRecord[EcosystemSpecific, DatabaseSpecific], err error) error) error {
+	if x.options.Concurrency <= 1 {
+		for _, file := range x.files {
+			if err := fn(x.readFile(file)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	jobs := make(chan *zip.File)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	var stopErr error
+	var wg sync.WaitGroup
+	for i := 0; i < x.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				if err := fn(x.readFile(file)); err != nil {
+					stopOnce.Do(func() {
+						stopErr = err
+						close(stop)
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for _, file := range x.files {
+		select {
+		case jobs <- file:
+		case <-stop:
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return stopErr
+}
+
+func (x *ZipReader[EcosystemSpecific, DatabaseSpecific]) readFile(file *zip.File) (*FileRecord[EcosystemSpecific, DatabaseSpecific], error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, &FileError{Path: file.Name, Err: err}
+	}
+	defer reader.Close()
+	r, err := Decode[EcosystemSpecific, DatabaseSpecific](context.Background(), reader, &DecodeOptions{Strict: x.options.Strict})
+	if err != nil {
+		return nil, &FileError{Path: file.Name, Err: err}
+	}
+	return &FileRecord[EcosystemSpecific, DatabaseSpecific]{Path: file.Name, Record: r}, nil
+}
+
+// ------------------------------------------------- --------------------------------------------------------------------
@@ -0,0 +1,85 @@
+package osv_schema
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestZip(t *testing.T, files map[string]string) []byte {
+	buffer := &bytes.Buffer{}
+	writer := zip.NewWriter(buffer)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w, err := writer.Create(name)
+		assert.Nil(t, err)
+		_, err = w.Write([]byte(files[name]))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func TestZipReader(t *testing.T) {
+	zipBytes := newTestZip(t, map[string]string{
+		"GHSA-1.json":  `{"id": "GHSA-1"}`,
+		"GHSA-2.json":  `{"id": 2}`,
+		"PYSEC-3.json": `{"id": "PYSEC-3"}`,
+		"README.md":    "not a record",
+	})
+
+	reader, err := NewZipReader[any, any](bytes.NewReader(zipBytes), int64(len(zipBytes)), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GHSA-1.json", "GHSA-2.json", "PYSEC-3.json"}, reader.Paths())
+	record, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "GHSA-1.json", record.Path)
+	assert.Equal(t, "GHSA-1", record.Record.ID)
+	_, err = reader.Next()
+	var fileError *FileError
+	assert.True(t, errors.As(err, &fileError))
+	assert.Equal(t, "GHSA-2.json", fileError.Path)
+	_, err = reader.Next()
+	assert.Nil(t, err)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+
+	path := filepath.Join(t.TempDir(), "all.zip")
+	assert.Nil(t, os.WriteFile(path, zipBytes, 0644))
+	reader, err = OpenZipFile[any, any](path, &ZipOptions{Concurrency: 4})
+	assert.Nil(t, err)
+	defer reader.Close()
+	var lock sync.Mutex
+	ids := make([]string, 0)
+	errorCount := 0
+	assert.Nil(t, reader.ForEach(func(record *FileRecord[any, any], err error) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if err != nil {
+			errorCount++
+			return nil
+		}
+		ids = append(ids, record.Record.ID)
+		return nil
+	}))
+	sort.Strings(ids)
+	assert.Equal(t, []string{"GHSA-1", "PYSEC-3"}, ids)
+	assert.Equal(t, 1, errorCount)
+
+	stopErr := errors.New("stop")
+	assert.Equal(t, stopErr, reader.ForEach(func(record *FileRecord[any, any], err error) error {
+		return stopErr
+	}))
+}